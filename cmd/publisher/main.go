@@ -2,19 +2,27 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"onlyfans-event-publisher/internal/config"
+	"onlyfans-event-publisher/internal/model"
 	"onlyfans-event-publisher/internal/publisher"
 	"onlyfans-event-publisher/internal/simulator"
+	"onlyfans-event-publisher/internal/sink"
 )
 
 func main() {
+	dryRun := flag.Bool("dry-run", false, "print records to stdout instead of publishing them (same as SINK=stdout)")
+	flag.Parse()
+
 	// Setup logging
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.Println("Starting OnlyFans Event Publisher...")
@@ -24,14 +32,86 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if *dryRun {
+		cfg.Sinks = []string{config.SinkStdout}
+	}
 
 	log.Printf("Configuration loaded:")
 	log.Printf("  Redpanda Brokers: %s", cfg.RedpandaBrokers)
+	log.Printf("  Sinks: %s", strings.Join(cfg.Sinks, ", "))
+	for _, name := range cfg.Sinks {
+		if policy := cfg.SinkPolicies[name]; policy != "" {
+			log.Printf("  %s Policy: %s", name, policy)
+		}
+		switch name {
+		case config.SinkMirror:
+			log.Printf("  Mirror Redpanda Brokers: %s", cfg.MirrorRedpandaBrokers)
+		case config.SinkStdout:
+			log.Printf("  Stdout Format: %s", cfg.StdoutSinkFormat)
+		case config.SinkFile:
+			log.Printf("  File Sink: %s (rotate at %d bytes / %dms, compression %s)",
+				cfg.FileSinkDir, cfg.FileSinkMaxBytes, cfg.FileSinkMaxAgeMs, cfg.FileSinkCompression)
+		case config.SinkParquet:
+			log.Printf("  Parquet Sink: %s (rotate at %d rows / %dms, compression %s)",
+				cfg.ParquetSinkDir, cfg.ParquetSinkMaxRows, cfg.ParquetSinkMaxAgeMs, cfg.ParquetSinkCompression)
+		case config.SinkWebhook:
+			log.Printf("  Webhooks: %s (batch %d, signed %t, timeout %dms, %d retries from %dms, dead letters to %s)",
+				cfg.WebhookURLs, cfg.WebhookBatchSize, cfg.WebhookSecret != "", cfg.WebhookTimeoutMs,
+				cfg.WebhookMaxRetries, cfg.WebhookBackoffMs, cfg.WebhookDeadLetterFile)
+		case config.SinkNATS:
+			log.Printf("  NATS: %s (stream %s, create %t, subjects %s.>, duplicate window %dms, ack timeout %dms)",
+				cfg.NATSURL, cfg.NATSStream, cfg.NATSCreateStream, cfg.NATSSubjectPrefix,
+				cfg.NATSDuplicateWindowMs, cfg.NATSAckTimeoutMs)
+		case config.SinkMQTT:
+			log.Printf("  MQTT: %s (topics %s/creators/..., QoS %d, retain status %t, retain content %t, publish timeout %dms)",
+				cfg.MQTTBroker, cfg.MQTTTopicPrefix, cfg.MQTTQoS, cfg.MQTTRetainStatus, cfg.MQTTRetainContent,
+				cfg.MQTTPublishTimeoutMs)
+		case config.SinkRedis:
+			log.Printf("  Redis Streams: prefix %q, max length %d (exact %t)",
+				cfg.RedisStreamPrefix, cfg.RedisMaxLen, cfg.RedisExactMaxLen)
+		}
+	}
 	log.Printf("  Content Topic: %s", cfg.ContentTopic)
 	log.Printf("  Creator Topic: %s", cfg.CreatorTopic)
+	log.Printf("  Live Topic: %s", cfg.LiveTopic)
+	log.Printf("  Comment Topic: %s", cfg.CommentTopic)
+	log.Printf("  Message Topic: %s", cfg.MessageTopic)
+	log.Printf("  Report Topic: %s", cfg.ReportTopic)
+	log.Printf("  Moderation Topic: %s", cfg.ModerationTopic)
+	log.Printf("  Payment Topics: %s, %s, %s", cfg.TxTopic, cfg.PayoutTopic, cfg.BalanceTopic)
+	log.Printf("  Fraud Label Topic: %s", cfg.FraudTopic)
+	log.Printf("  Promotion Topic: %s", cfg.PromotionTopic)
+	log.Printf("  Exchange Rate Topic: %s", cfg.FxTopic)
+	if cfg.TopicRoutesFile != "" {
+		log.Printf("  Topic Routes: %s", cfg.TopicRoutesFile)
+	}
 	log.Printf("  Number of Creators: %d", cfg.NumCreators)
 	log.Printf("  Interval: %dms", cfg.IntervalMs)
 	log.Printf("  Abnormal Probability: %.2f", cfg.AbnormalProbability)
+	log.Printf("  Viral Probability: %.2f", cfg.ViralProbability)
+	log.Printf("  Live Probability: %.2f", cfg.LiveProbability)
+	log.Printf("  Comment Probability: %.2f", cfg.CommentProbability)
+	log.Printf("  Message Probability: %.2f", cfg.MessageProbability)
+	log.Printf("  Report Probability: %.3f", cfg.ReportProbability)
+	log.Printf("  Promotion Probability: %.3f", cfg.PromotionProbability)
+	log.Printf("  Creator Countries: %s", cfg.CreatorCountryWeights)
+	log.Printf("  Fan Countries: %s", cfg.FanCountryWeights)
+	log.Printf("  Exchange Rate Interval: %dms", cfg.ExchangeRateIntervalMs)
+	log.Printf("  Legacy Float Money: %t", cfg.LegacyFloatMoney)
+	log.Printf("  Event Envelope: %t", cfg.EventEnvelope)
+	log.Printf("  Chaos Rate: %.3f, Chaos Kinds: %q", cfg.ChaosRate, cfg.ChaosKinds)
+	log.Printf("  Late Rate: %.3f (up to %dms), Reorder Window: %d, Duplicate Rate: %.3f",
+		cfg.LateRate, cfg.MaxLatenessMs, cfg.ReorderWindow, cfg.DuplicateRate)
+	log.Printf("  Validate Events: %t, Reject Topic: %q", cfg.ValidateEvents, cfg.RejectTopic)
+	log.Printf("  Platform Fee: %.2f", cfg.PlatformFee)
+	log.Printf("  Payout Interval: %dms", cfg.PayoutIntervalMs)
+	log.Printf("  Refund Rate: %.3f, Chargeback Rate: %.3f, Fraud Fans: %.3f",
+		cfg.RefundRate, cfg.ChargebackRate, cfg.FraudFanFraction)
+	log.Printf("  Fraud Rates: card testing %.3f, like farm %.3f, account takeover %.3f, collusion ring %.3f",
+		cfg.FraudCardTestingRate, cfg.FraudLikeFarmRate, cfg.FraudAccountTakeoverRate, cfg.FraudCollusionRingRate)
+	log.Printf("  Subscriber Distribution: %s", cfg.SubscriberDistribution)
+	log.Printf("  Activity Distribution: %s", cfg.ActivityDistribution)
+	log.Printf("  Engagement Distribution: %s", cfg.EngagementDistribution)
 
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -39,14 +119,19 @@ func main() {
 
 	// Create platform simulator
 	log.Printf("Initializing platform simulator with %d creators...", cfg.NumCreators)
-	sim := simulator.NewPlatformSimulator(cfg.NumCreators, cfg.AbnormalProbability)
+	simOpts, err := buildSimulatorOptions(cfg)
+	if err != nil {
+		log.Fatalf("Invalid simulator configuration: %v", err)
+	}
+	sim := simulator.NewPlatformSimulator(simOpts)
+	model.LegacyFloatMoney = cfg.LegacyFloatMoney
 
 	// Log initial creators
 	creators := sim.GetCreators()
 	log.Printf("Created %d creators:", len(creators))
 	for i, creator := range creators {
 		if i < 3 { // Log first 3 creators as examples
-			log.Printf("  - %s (%s): %d subscribers, $%.2f/month, %s",
+			log.Printf("  - %s (%s): %d subscribers, %s/month, %s",
 				creator.Username, creator.DisplayName, creator.SubscriberCount,
 				creator.MonthlyPrice, creator.Category)
 		}
@@ -55,16 +140,87 @@ func main() {
 		log.Printf("  ... and %d more creators", len(creators)-3)
 	}
 
+	var router *publisher.Router
+	if cfg.TopicRoutesFile != "" {
+		router, err = publisher.LoadRouter(cfg.TopicRoutesFile)
+		if err != nil {
+			log.Fatalf("Invalid topic routes: %v", err)
+		}
+	}
+
 	// Create platform publisher
-	log.Println("Connecting to Redpanda cluster...")
-	pub, err := publisher.NewPlatformPublisher(ctx, cfg.RedpandaBrokers, cfg.ContentTopic, cfg.CreatorTopic)
+	out, err := newSink(ctx, cfg, creators, router)
 	if err != nil {
-		log.Fatalf("Failed to create publisher: %v", err)
+		log.Fatalf("Failed to create sink: %v", err)
+	}
+	pub := publisher.NewPublisher(out, publisher.Topics{
+		Content:      cfg.ContentTopic,
+		Creator:      cfg.CreatorTopic,
+		Live:         cfg.LiveTopic,
+		Comment:      cfg.CommentTopic,
+		Message:      cfg.MessageTopic,
+		Report:       cfg.ReportTopic,
+		Moderation:   cfg.ModerationTopic,
+		Transaction:  cfg.TxTopic,
+		Payout:       cfg.PayoutTopic,
+		Balance:      cfg.BalanceTopic,
+		FraudLabel:   cfg.FraudTopic,
+		Promotion:    cfg.PromotionTopic,
+		ExchangeRate: cfg.FxTopic,
+		Reject:       cfg.RejectTopic,
+	})
+	defer func() {
+		if err := pub.Close(); err != nil {
+			log.Printf("Error closing sink: %v", err)
+		}
+	}()
+
+	if router != nil {
+		pub.UseRouter(router)
+		log.Printf("Routing events to %d topics by the rules in %s", len(router.Topics()), cfg.TopicRoutesFile)
+	}
+
+	partitioned := make([]string, 0, len(cfg.Partitioning))
+	for topic := range cfg.Partitioning {
+		partitioned = append(partitioned, topic)
+	}
+	sort.Strings(partitioned)
+	for _, topic := range partitioned {
+		log.Printf("Partitioning %s by %s", topic, cfg.Partitioning[topic])
+	}
+
+	if cfg.EventEnvelope {
+		sequencer := model.NewSequencer(cfg.ProducerID)
+		pub.UseEnvelopes(sequencer)
+		log.Printf("Wrapping events in envelopes as producer %s", sequencer.ProducerID())
+	}
+
+	var chaos *publisher.Chaos
+	if cfg.ChaosRate > 0 {
+		chaos, err = publisher.NewChaos(cfg.ChaosRate, publisher.ParseChaosKinds(cfg.ChaosKinds))
+		if err != nil {
+			log.Fatalf("Invalid chaos configuration: %v", err)
+		}
+		pub.UseChaos(chaos)
+		log.Printf("Corrupting %.1f%% of records", cfg.ChaosRate*100)
+	}
+
+	var disorder *publisher.Disorder
+	if cfg.LateRate > 0 || cfg.ReorderWindow > 1 || cfg.DuplicateRate > 0 {
+		disorder, err = publisher.NewDisorder(publisher.DisorderOptions{
+			LateRate:      cfg.LateRate,
+			MaxLateness:   time.Duration(cfg.MaxLatenessMs) * time.Millisecond,
+			ReorderWindow: cfg.ReorderWindow,
+			DuplicateRate: cfg.DuplicateRate,
+		})
+		if err != nil {
+			log.Fatalf("Invalid disorder configuration: %v", err)
+		}
+		pub.UseDisorder(disorder)
 	}
-	defer pub.Close()
 
 	contentTopic, creatorTopic := pub.GetTopics()
-	log.Printf("Connected to Redpanda - Content Topic: %s, Creator Topic: %s", contentTopic, creatorTopic)
+	log.Printf("Publishing via %s - Content Topic: %s, Creator Topic: %s, Live Topic: %s", strings.Join(cfg.Sinks, ", "), contentTopic, creatorTopic, cfg.LiveTopic)
 
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -82,20 +238,36 @@ func main() {
 	log.Println("Starting simulation loop...")
 	log.Println("Press Ctrl+C to stop gracefully")
 
+	// shutdown publishes held back records and prints the final statistics; ctx
+	// may already be cancelled, so flushing gets its own deadline
+	shutdown := func() {
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelFlush()
+		if err := pub.Flush(flushCtx); err != nil {
+			log.Printf("Error flushing held records: %v", err)
+		}
+		printFinalStats(stats)
+		printChaosStats(chaos)
+		printDisorderStats(disorder)
+		printRouterStats(router)
+		printSinkStats(out)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			log.Println("Context cancelled, shutting down...")
+			shutdown()
 			return
 
 		case <-sigChan:
 			log.Println("Received shutdown signal")
-			printFinalStats(stats)
+			shutdown()
 			cancel()
 			return
 
 		case <-ticker.C:
-			if err := runSimulationCycle(ctx, sim, pub, stats); err != nil {
+			if err := runSimulationCycle(ctx, sim, pub, stats, cfg.ValidateEvents); err != nil {
 				log.Printf("Error in simulation cycle: %v", err)
 				// Continue running even if there's an error
 			}
@@ -103,42 +275,170 @@ func main() {
 			// Print stats every minute
 			if stats.Cycles%60 == 0 && stats.Cycles > 0 {
 				printPeriodicStats(stats)
+				printSinkStats(out)
 			}
 		}
 	}
 }
 
+// buildSimulatorOptions converts the loaded configuration into simulator options
+func buildSimulatorOptions(cfg *config.Config) (simulator.Options, error) {
+	subscriberDist, err := simulator.ParseDistribution(cfg.SubscriberDistribution)
+	if err != nil {
+		return simulator.Options{}, fmt.Errorf("SUBSCRIBER_DISTRIBUTION: %w", err)
+	}
+
+	activityDist, err := simulator.ParseDistribution(cfg.ActivityDistribution)
+	if err != nil {
+		return simulator.Options{}, fmt.Errorf("ACTIVITY_DISTRIBUTION: %w", err)
+	}
+
+	engagementDist, err := simulator.ParseDistribution(cfg.EngagementDistribution)
+	if err != nil {
+		return simulator.Options{}, fmt.Errorf("ENGAGEMENT_DISTRIBUTION: %w", err)
+	}
+
+	creatorCountries, err := simulator.ParseCountryWeights(cfg.CreatorCountryWeights)
+	if err != nil {
+		return simulator.Options{}, fmt.Errorf("CREATOR_COUNTRY_WEIGHTS: %w", err)
+	}
+
+	fanCountries, err := simulator.ParseCountryWeights(cfg.FanCountryWeights)
+	if err != nil {
+		return simulator.Options{}, fmt.Errorf("FAN_COUNTRY_WEIGHTS: %w", err)
+	}
+
+	return simulator.Options{
+		NumCreators:          cfg.NumCreators,
+		AbnormalProbability:  cfg.AbnormalProbability,
+		ViralProbability:     cfg.ViralProbability,
+		LiveProbability:      cfg.LiveProbability,
+		CommentProbability:   cfg.CommentProbability,
+		MessageProbability:   cfg.MessageProbability,
+		ReportProbability:    cfg.ReportProbability,
+		PromotionProbability: cfg.PromotionProbability,
+		CreatorCountries:     creatorCountries,
+		FanCountries:         fanCountries,
+		ExchangeRateInterval: time.Duration(cfg.ExchangeRateIntervalMs) * time.Millisecond,
+		PlatformFee:          cfg.PlatformFee,
+		PayoutInterval:       time.Duration(cfg.PayoutIntervalMs) * time.Millisecond,
+		RefundRate:           cfg.RefundRate,
+		ChargebackRate:       cfg.ChargebackRate,
+		FraudFanFraction:     cfg.FraudFanFraction,
+		Fraud: simulator.FraudRates{
+			CardTesting:     cfg.FraudCardTestingRate,
+			LikeFarm:        cfg.FraudLikeFarmRate,
+			AccountTakeover: cfg.FraudAccountTakeoverRate,
+			CollusionRing:   cfg.FraudCollusionRingRate,
+		},
+		SubscriberDistribution: subscriberDist,
+		ActivityDistribution:   activityDist,
+		EngagementDistribution: engagementDist,
+	}, nil
+}
+
 // Statistics holds runtime statistics
 type Statistics struct {
 	StartTime        time.Time
 	Cycles           int64
 	ContentPublished int64
+	ViralUpdates     int64
 	CreatorUpdates   int64
+	LiveEvents       int64
+	Comments         int64
+	Messages         int64
+	ModerationEvents int64
+	PaymentEvents    int64
+	FraudLabels      int64
+	PromotionEvents  int64
+	ExchangeRates    int64
 	PublishErrors    int64
+	Rejected         int64 // Events that failed validation
+	Anomalous        int64 // Valid events showing signs of fraud
 	LastContentCount int
 	LastCreatorCount int
 }
 
+// TotalEvents returns the number of events published so far
+func (s *Statistics) TotalEvents() int64 {
+	return s.ContentPublished + s.ViralUpdates + s.CreatorUpdates + s.LiveEvents + s.Comments + s.Messages + s.ModerationEvents + s.PaymentEvents + s.FraudLabels + s.PromotionEvents + s.ExchangeRates
+}
+
 // runSimulationCycle runs one cycle of the simulation
-func runSimulationCycle(ctx context.Context, sim *simulator.PlatformSimulator, pub *publisher.PlatformPublisher, stats *Statistics) error {
+func runSimulationCycle(ctx context.Context, sim *simulator.PlatformSimulator, pub *publisher.PlatformPublisher, stats *Statistics, validate bool) error {
 	// Generate content and creator updates
+	// Moderation runs first so suspensions and removals apply to this cycle
+	moderation := sim.GenerateModerationEvents()
+	exchangeRates := sim.GenerateExchangeRates()
+	fraud := sim.GenerateFraud()
+	promotions := sim.GeneratePromotions()
+	viralUpdates := sim.AdvanceViralContent()
 	newContent := sim.GenerateContent()
 	creatorUpdates := sim.GenerateCreatorUpdates()
+	liveEvents, liveReplays := sim.GenerateLiveEvents()
+	comments := sim.GenerateComments()
+	messages := sim.GenerateMessages()
+	payments := sim.GeneratePayments() // Last, so it collects tips and unlocks from this cycle
 
 	stats.Cycles++
 	stats.LastContentCount = len(newContent)
 	stats.LastCreatorCount = len(creatorUpdates)
 
+	// Viral and like farm updates and live replays are content too, so they go to the content topic
+	contentEvents := append(append(newContent, viralUpdates...), liveReplays...)
+	batch := publisher.Batch{
+		Content:  append(contentEvents, fraud.ContentUpdates...),
+		Creators: creatorUpdates,
+		Live:     liveEvents,
+		Comments: comments,
+		Messages: messages,
+
+		Reports:        moderation.Reports,
+		Decisions:      moderation.Decisions,
+		RemovedContent: moderation.RemovedContent,
+
+		Transactions: payments.Transactions,
+		Payouts:      payments.Payouts,
+		Balances:     payments.Balances,
+
+		FraudLabels: fraud.Labels,
+		Promotions:  promotions,
+
+		ExchangeRates: exchangeRates,
+	}
+
+	if validate {
+		batch = batch.Validate()
+		stats.Rejected += int64(len(batch.Rejects))
+		for _, reject := range batch.Rejects {
+			log.Printf("Rejected %s %s: %s", reject.EventType, reject.Key, strings.Join(reject.Problems, "; "))
+		}
+		for _, content := range batch.Content {
+			if len(content.Anomalies()) > 0 {
+				stats.Anomalous++
+			}
+		}
+	}
+
 	// Publish to Redpanda if we have data
-	if len(newContent) > 0 || len(creatorUpdates) > 0 {
-		// Use mixed publishing for efficiency
-		if err := pub.PublishMixed(ctx, newContent, creatorUpdates); err != nil {
+	if batch.Len() > 0 {
+		// Publish everything in one batch for efficiency
+		if err := pub.PublishBatch(ctx, batch); err != nil {
 			stats.PublishErrors++
 			return fmt.Errorf("failed to publish events: %w", err)
 		}
 
-		stats.ContentPublished += int64(len(newContent))
+		stats.ContentPublished += int64(len(newContent) + len(liveReplays))
+		stats.ViralUpdates += int64(len(viralUpdates))
 		stats.CreatorUpdates += int64(len(creatorUpdates))
+		stats.LiveEvents += int64(len(liveEvents))
+		stats.Comments += int64(len(comments))
+		stats.Messages += int64(len(messages))
+		stats.FraudLabels += int64(len(fraud.Labels))
+		stats.PromotionEvents += int64(len(promotions))
+		stats.ExchangeRates += int64(len(exchangeRates))
+		stats.PaymentEvents += int64(len(payments.Transactions) + len(payments.Payouts) + len(payments.Balances))
+		stats.ModerationEvents += int64(len(moderation.Reports) + len(moderation.Decisions) + len(moderation.RemovedContent))
 
 		// Log activity
 		if len(newContent) > 0 && len(creatorUpdates) > 0 {
@@ -148,6 +448,34 @@ func runSimulationCycle(ctx context.Context, sim *simulator.PlatformSimulator, p
 		} else if len(creatorUpdates) > 0 {
 			log.Printf("Published %d creator updates", len(creatorUpdates))
 		}
+		if len(viralUpdates) > 0 {
+			log.Printf("Published %d viral content updates", len(viralUpdates))
+		}
+		if len(liveEvents) > 0 {
+			log.Printf("Published %d live session events (%d replays)", len(liveEvents), len(liveReplays))
+		}
+		if len(comments) > 0 || len(messages) > 0 {
+			log.Printf("Published %d comments and %d direct messages", len(comments), len(messages))
+		}
+		if len(moderation.Reports) > 0 || len(moderation.Decisions) > 0 {
+			log.Printf("Published %d reports and %d moderation decisions (%d content removed)",
+				len(moderation.Reports), len(moderation.Decisions), len(moderation.RemovedContent))
+		}
+		if len(payments.Transactions) > 0 {
+			log.Printf("Published %d transactions", len(payments.Transactions))
+		}
+		if len(exchangeRates) > 0 {
+			log.Printf("Published exchange rate snapshot")
+		}
+		if len(promotions) > 0 {
+			log.Printf("Published %d promotion events", len(promotions))
+		}
+		for _, label := range fraud.Labels {
+			log.Printf("Injected fraud scenario %s (%s): %s", label.ID, label.Scenario, label.Description)
+		}
+		if len(payments.Payouts) > 0 || len(payments.Balances) > 0 {
+			log.Printf("Published %d payouts and %d balance snapshots", len(payments.Payouts), len(payments.Balances))
+		}
 
 		// Log some sample content for debugging
 		if len(newContent) > 0 {
@@ -169,12 +497,103 @@ func printPeriodicStats(stats *Statistics) {
 	log.Printf("=== Statistics (Uptime: %v) ===", uptime.Round(time.Second))
 	log.Printf("Cycles: %d", stats.Cycles)
 	log.Printf("Content Published: %d (%.1f/min)", stats.ContentPublished, avgContentPerMin)
+	log.Printf("Viral Updates: %d", stats.ViralUpdates)
 	log.Printf("Creator Updates: %d (%.1f/min)", stats.CreatorUpdates, avgCreatorPerMin)
-	log.Printf("Publish Errors: %d", stats.PublishErrors)
+	log.Printf("Live Events: %d", stats.LiveEvents)
+	log.Printf("Comments: %d, Messages: %d", stats.Comments, stats.Messages)
+	log.Printf("Moderation Events: %d", stats.ModerationEvents)
+	log.Printf("Payment Events: %d", stats.PaymentEvents)
+	log.Printf("Fraud Labels: %d", stats.FraudLabels)
+	log.Printf("Promotion Events: %d", stats.PromotionEvents)
+	log.Printf("Publish Errors: %d, Rejected Events: %d, Anomalous Events: %d", stats.PublishErrors, stats.Rejected, stats.Anomalous)
 	log.Printf("Last Cycle: %d content, %d creators", stats.LastContentCount, stats.LastCreatorCount)
 	log.Printf("===============================")
 }
 
+// printChaosStats prints how many records were corrupted, per kind
+func printChaosStats(chaos *publisher.Chaos) {
+	if chaos == nil {
+		return
+	}
+
+	counts := chaos.Counts()
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	log.Println("=== Chaos Payloads ===")
+	for _, kind := range kinds {
+		log.Printf("%s: %d", kind, counts[kind])
+	}
+}
+
+// printDisorderStats prints how many records were delayed, reordered and duplicated
+func printDisorderStats(disorder *publisher.Disorder) {
+	if disorder == nil {
+		return
+	}
+
+	stats := disorder.Stats()
+	log.Println("=== Disorder ===")
+	log.Printf("Delayed: %d, Reordered: %d, Duplicated: %d", stats.Delayed, stats.Reordered, stats.Duplicated)
+}
+
+// printRouterStats prints how many events were routed to each topic
+func printRouterStats(router *publisher.Router) {
+	if router == nil {
+		return
+	}
+
+	counts := router.Counts()
+	topics := make([]string, 0, len(counts))
+	for topic := range counts {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	log.Println("=== Topic Routes ===")
+	for _, topic := range topics {
+		log.Printf("%s: %d", topic, counts[topic])
+	}
+}
+
+// printSinkStats prints delivery counts for sinks that keep them
+func printSinkStats(out sink.Sink) {
+	switch out := out.(type) {
+	case *sink.Multi:
+		log.Println("=== Sinks ===")
+		for _, stats := range out.Stats() {
+			log.Printf("%s (%s): %d batches, %d records, %d failures, %d retried, %d buffered, %d dropped, %v writing",
+				stats.Name, stats.Policy, stats.Batches, stats.Records, stats.Failures, stats.Retried,
+				stats.Buffered, stats.Dropped, stats.WriteTime.Round(time.Millisecond))
+			if stats.LastError != "" {
+				log.Printf("%s last error: %s", stats.Name, stats.LastError)
+			}
+		}
+		for _, s := range out.Sinks() {
+			printSinkStats(s)
+		}
+	case *sink.Webhook:
+		stats := out.Stats()
+		log.Println("=== Webhooks ===")
+		log.Printf("Requests: %d, Events Delivered: %d, Retries: %d, Dead-Lettered: %d",
+			stats.Requests, stats.Delivered, stats.Retries, stats.DeadLettered)
+	case *sink.NATS:
+		stats := out.Stats()
+		log.Println("=== NATS ===")
+		log.Printf("Published: %d, Duplicates Dropped: %d", stats.Published, stats.Duplicates)
+	case *sink.MQTT:
+		stats := out.Stats()
+		log.Println("=== MQTT ===")
+		log.Printf("Published: %d, Retained: %d", stats.Published, stats.Retained)
+	case *sink.Redis:
+		log.Println("=== Redis Streams ===")
+		log.Printf("Entries Added: %d", out.Stats().Added)
+	}
+}
+
 // printFinalStats prints final statistics on shutdown
 func printFinalStats(stats *Statistics) {
 	uptime := time.Since(stats.StartTime)
@@ -183,12 +602,20 @@ func printFinalStats(stats *Statistics) {
 	log.Printf("Total Uptime: %v", uptime.Round(time.Second))
 	log.Printf("Total Cycles: %d", stats.Cycles)
 	log.Printf("Content Published: %d", stats.ContentPublished)
+	log.Printf("Viral Updates: %d", stats.ViralUpdates)
 	log.Printf("Creator Updates: %d", stats.CreatorUpdates)
-	log.Printf("Total Events: %d", stats.ContentPublished+stats.CreatorUpdates)
+	log.Printf("Live Events: %d", stats.LiveEvents)
+	log.Printf("Comments: %d, Messages: %d", stats.Comments, stats.Messages)
+	log.Printf("Moderation Events: %d", stats.ModerationEvents)
+	log.Printf("Payment Events: %d", stats.PaymentEvents)
+	log.Printf("Fraud Labels: %d", stats.FraudLabels)
+	log.Printf("Promotion Events: %d", stats.PromotionEvents)
+	log.Printf("Total Events: %d", stats.TotalEvents())
 	log.Printf("Publish Errors: %d", stats.PublishErrors)
+	log.Printf("Rejected Events: %d, Anomalous Events: %d", stats.Rejected, stats.Anomalous)
 
 	if uptime.Minutes() > 0 {
-		log.Printf("Average Events/min: %.1f", float64(stats.ContentPublished+stats.CreatorUpdates)/uptime.Minutes())
+		log.Printf("Average Events/min: %.1f", float64(stats.TotalEvents())/uptime.Minutes())
 	}
 
 	if stats.Cycles > 0 {
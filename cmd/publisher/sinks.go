@@ -0,0 +1,430 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"onlyfans-event-publisher/internal/config"
+	"onlyfans-event-publisher/internal/model"
+	"onlyfans-event-publisher/internal/publisher"
+	"onlyfans-event-publisher/internal/sink"
+)
+
+// newSink creates the sinks listed in SINK, routing every batch to each of
+// them under its SINK_POLICIES policy when there are several or a policy other
+// than fail-fast is set. Creators give the NATS sink the categories of their
+// content, and router the topics events may be routed to.
+func newSink(ctx context.Context, cfg *config.Config, creators []model.Creator, router *publisher.Router) (sink.Sink, error) {
+	tables := topicTables(cfg, router)
+
+	var routes []sink.Route
+	closeAll := func() {
+		for _, r := range routes {
+			r.Sink.Close()
+		}
+	}
+
+	for _, name := range cfg.Sinks {
+		s, err := newNamedSink(ctx, cfg, name, creators, tables)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("%s sink: %w", name, err)
+		}
+		routes = append(routes, sink.Route{
+			Name:        name,
+			Sink:        s,
+			Policy:      cfg.SinkPolicies[name],
+			RetryBuffer: cfg.SinkRetryBuffer,
+		})
+	}
+
+	if len(routes) == 1 && (routes[0].Policy == "" || routes[0].Policy == sink.PolicyFailFast) {
+		return routes[0].Sink, nil
+	}
+	multi, err := sink.NewMulti(routes...)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return multi, nil
+}
+
+// newNamedSink creates one sink; tables maps every topic to its events
+func newNamedSink(ctx context.Context, cfg *config.Config, name string, creators []model.Creator, tables map[string]sink.TopicTable) (sink.Sink, error) {
+	switch name {
+	case config.SinkKafka:
+		log.Println("Connecting to Redpanda cluster...")
+		partitioner, err := newPartitioner(cfg, creators, tables)
+		if err != nil {
+			return nil, err
+		}
+		return sink.NewKafka(ctx, cfg.RedpandaBrokers, kgo.RecordPartitioner(partitioner))
+
+	case config.SinkMirror:
+		log.Println("Connecting to mirror Redpanda cluster...")
+		partitioner, err := newPartitioner(cfg, creators, tables)
+		if err != nil {
+			return nil, err
+		}
+		return sink.NewKafka(ctx, cfg.MirrorRedpandaBrokers, kgo.RecordPartitioner(partitioner))
+
+	case config.SinkStdout:
+		return sink.NewStdout(os.Stdout, cfg.StdoutSinkFormat)
+
+	case config.SinkFile:
+		log.Printf("Writing NDJSON files to %s", cfg.FileSinkDir)
+		return sink.NewFile(sink.FileOptions{
+			Dir:         cfg.FileSinkDir,
+			MaxBytes:    int64(cfg.FileSinkMaxBytes),
+			MaxAge:      time.Duration(cfg.FileSinkMaxAgeMs) * time.Millisecond,
+			Compression: cfg.FileSinkCompression,
+		})
+
+	case config.SinkParquet:
+		log.Printf("Writing Parquet files to %s", cfg.ParquetSinkDir)
+		return sink.NewParquet(sink.ParquetOptions{
+			Dir:         cfg.ParquetSinkDir,
+			Tables:      tables,
+			MaxRows:     int64(cfg.ParquetSinkMaxRows),
+			MaxAge:      time.Duration(cfg.ParquetSinkMaxAgeMs) * time.Millisecond,
+			Compression: cfg.ParquetSinkCompression,
+		})
+
+	case config.SinkWebhook:
+		urls, err := webhookURLs(cfg, tables)
+		if err != nil {
+			return nil, fmt.Errorf("WEBHOOK_URLS: %w", err)
+		}
+		log.Printf("Posting events to %d webhook endpoints", len(urls))
+		return sink.NewWebhook(sink.WebhookOptions{
+			URLs:           urls,
+			Secret:         cfg.WebhookSecret,
+			BatchSize:      cfg.WebhookBatchSize,
+			Timeout:        time.Duration(cfg.WebhookTimeoutMs) * time.Millisecond,
+			MaxRetries:     cfg.WebhookMaxRetries,
+			Backoff:        time.Duration(cfg.WebhookBackoffMs) * time.Millisecond,
+			DeadLetterPath: cfg.WebhookDeadLetterFile,
+		})
+
+	case config.SinkNATS:
+		log.Printf("Connecting to NATS at %s...", cfg.NATSURL)
+		return sink.NewNATS(ctx, sink.NATSOptions{
+			URL:          cfg.NATSURL,
+			Stream:       cfg.NATSStream,
+			CreateStream: cfg.NATSCreateStream,
+			Subjects:     []string{cfg.NATSSubjectPrefix + ".>"},
+			Duplicates:   time.Duration(cfg.NATSDuplicateWindowMs) * time.Millisecond,
+			AckTimeout:   time.Duration(cfg.NATSAckTimeoutMs) * time.Millisecond,
+			Subject:      natsSubjects(cfg, creators, tables),
+		})
+
+	case config.SinkMQTT:
+		log.Printf("Connecting to MQTT broker at %s...", cfg.MQTTBroker)
+		return sink.NewMQTT(sink.MQTTOptions{
+			Broker:         cfg.MQTTBroker,
+			ClientID:       cfg.MQTTClientID,
+			QoS:            byte(cfg.MQTTQoS),
+			PublishTimeout: time.Duration(cfg.MQTTPublishTimeoutMs) * time.Millisecond,
+			Messages:       mqttMessages(cfg, tables),
+		})
+
+	case config.SinkRedis:
+		log.Println("Connecting to Redis...")
+		return sink.NewRedis(ctx, sink.RedisOptions{
+			URL:          cfg.RedisURL,
+			StreamPrefix: cfg.RedisStreamPrefix,
+			MaxLen:       int64(cfg.RedisMaxLen),
+			ExactMaxLen:  cfg.RedisExactMaxLen,
+		})
+
+	default:
+		return nil, fmt.Errorf("unknown sink %q", name)
+	}
+}
+
+// topicTables maps each topic to the events published to it, including the
+// topics router may send them to
+func topicTables(cfg *config.Config, router *publisher.Router) map[string]sink.TopicTable {
+	tables := map[string]sink.TopicTable{
+		cfg.ContentTopic:    {EventType: model.EventContent, Event: model.Content{}},
+		cfg.CreatorTopic:    {EventType: model.EventCreator, Event: model.Creator{}},
+		cfg.LiveTopic:       {EventType: model.EventLive, Event: model.LiveEvent{}},
+		cfg.CommentTopic:    {EventType: model.EventComment, Event: model.Comment{}},
+		cfg.MessageTopic:    {EventType: model.EventMessage, Event: model.Message{}},
+		cfg.ReportTopic:     {EventType: model.EventReport, Event: model.Report{}},
+		cfg.ModerationTopic: {EventType: model.EventModerationDecision, Event: model.ModerationDecision{}},
+		cfg.TxTopic:         {EventType: model.EventTransaction, Event: model.Transaction{}},
+		cfg.PayoutTopic:     {EventType: model.EventPayout, Event: model.Payout{}},
+		cfg.BalanceTopic:    {EventType: model.EventBalanceSnapshot, Event: model.BalanceSnapshot{}},
+		cfg.FraudTopic:      {EventType: model.EventFraudLabel, Event: model.FraudLabel{}},
+		cfg.PromotionTopic:  {EventType: model.EventPromotion, Event: model.PromotionEvent{}},
+		cfg.FxTopic:         {EventType: model.EventExchangeRates, Event: model.ExchangeRateSnapshot{}},
+	}
+	if cfg.RejectTopic != "" {
+		tables[cfg.RejectTopic] = sink.TopicTable{EventType: model.EventRejected, Event: model.RejectedEvent{}}
+	}
+
+	byEventType := make(map[string]sink.TopicTable, len(tables))
+	for _, table := range tables {
+		byEventType[table.EventType] = table
+	}
+	for topic, eventType := range router.Topics() {
+		if table, ok := byEventType[eventType]; ok {
+			tables[topic] = table
+		}
+	}
+	return tables
+}
+
+// webhookURLs resolves WEBHOOK_URLS, a list of EVENT_TYPE=URL pairs, to a URL
+// per topic. Event types without an entry use the "*" entry, if any.
+func webhookURLs(cfg *config.Config, tables map[string]sink.TopicTable) (map[string]string, error) {
+	byEventType := make(map[string]string)
+	for _, pair := range strings.Split(cfg.WebhookURLs, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		eventType, url, ok := strings.Cut(pair, "=")
+		if !ok || url == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected EVENT_TYPE=URL", pair)
+		}
+		byEventType[strings.TrimSpace(eventType)] = strings.TrimSpace(url)
+	}
+
+	known := map[string]bool{"*": true, model.EventRejected: true}
+	urls := make(map[string]string)
+	for topic, table := range tables {
+		known[table.EventType] = true
+		if url, ok := byEventType[table.EventType]; ok {
+			urls[topic] = url
+		} else if url, ok := byEventType["*"]; ok {
+			urls[topic] = url
+		}
+	}
+	for eventType := range byEventType {
+		if !known[eventType] {
+			return nil, fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+	return urls, nil
+}
+
+// natsSubjects returns the NATS subject function: content goes to
+// <prefix>.content.<creator category>, creators to <prefix>.creator.<id> and
+// everything else to <prefix>.<event type>. Tombstones are not published.
+func natsSubjects(cfg *config.Config, creators []model.Creator, tables map[string]sink.TopicTable) func(*kgo.Record) (string, bool) {
+	categories := make(map[string]string, len(creators))
+	for _, creator := range creators {
+		categories[creator.ID] = creator.Category
+	}
+
+	return func(record *kgo.Record) (string, bool) {
+		table, ok := tables[record.Topic]
+		if !ok || record.Value == nil {
+			return "", false
+		}
+
+		switch table.EventType {
+		case model.EventContent:
+			category := "unknown"
+			var content model.Content
+			if decodePayload(record.Value, &content) == nil && categories[content.CreatorID] != "" {
+				category = categories[content.CreatorID]
+			}
+			return cfg.NATSSubjectPrefix + ".content." + subjectToken(category), true
+		case model.EventCreator:
+			return cfg.NATSSubjectPrefix + ".creator." + subjectToken(string(record.Key)), true
+		default:
+			return cfg.NATSSubjectPrefix + "." + table.EventType, true
+		}
+	}
+}
+
+// newPartitioner returns the Kafka partitioner for PARTITIONING. It reads
+// the creator ID, content ID or category to hash from the record value;
+// tables says which topics carry creators and content, whose own ID is "id".
+// Tombstones have no value, so their content ID is their key and their
+// creator ID is in a header. Category partitioning reads a creator's category
+// from creator records and looks it up by creator ID for every other event.
+func newPartitioner(cfg *config.Config, creators []model.Creator, tables map[string]sink.TopicTable) (kgo.Partitioner, error) {
+	categories := make(map[string]string, len(creators))
+	for _, creator := range creators {
+		categories[creator.ID] = creator.Category
+	}
+
+	return sink.NewPartitioner(cfg.Partitioning, func(record *kgo.Record, strategy string) string {
+		eventType := tables[record.Topic].EventType
+		var event struct {
+			ID        string `json:"id"`
+			CreatorID string `json:"creator_id"`
+			ContentID string `json:"content_id"`
+			Category  string `json:"category"`
+		}
+		if record.Value == nil {
+			for _, header := range record.Headers {
+				if header.Key == publisher.CreatorIDHeader {
+					event.CreatorID = string(header.Value)
+				}
+			}
+			if eventType == model.EventContent {
+				event.ContentID = string(record.Key)
+			}
+		} else if decodePayload(record.Value, &event) != nil {
+			return ""
+		}
+		switch eventType {
+		case model.EventCreator:
+			event.CreatorID = event.ID
+		case model.EventContent:
+			if event.ID != "" {
+				event.ContentID = event.ID
+			}
+		}
+
+		switch strategy {
+		case sink.PartitionCreatorID:
+			return event.CreatorID
+		case sink.PartitionContentID:
+			return event.ContentID
+		}
+		if event.Category != "" {
+			return event.Category
+		}
+		return categories[event.CreatorID]
+	})
+}
+
+// decodePayload decodes a record value into event, unwrapping the envelope if
+// there is one
+func decodePayload(value []byte, event any) error {
+	var envelope model.Envelope
+	if err := json.Unmarshal(value, &envelope); err == nil && envelope.EventID != "" {
+		value = envelope.Payload
+	}
+	return json.Unmarshal(value, event)
+}
+
+// subjectToken replaces characters that NATS gives a meaning in subjects
+func subjectToken(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// creatorStatus is the MQTT message sent when a creator goes online or offline
+type creatorStatus struct {
+	CreatorID string    `json:"creator_id"`
+	Username  string    `json:"username"`
+	IsOnline  bool      `json:"is_online"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// contentNotification is the MQTT message sent when a creator posts
+type contentNotification struct {
+	ContentID   string      `json:"content_id"`
+	CreatorID   string      `json:"creator_id"`
+	Title       string      `json:"title"`
+	ContentType string      `json:"content_type"`
+	IsLocked    bool        `json:"is_locked"`
+	Price       model.Money `json:"price_money"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// mqttMessages returns the MQTT message function: a creator's online status
+// goes to <prefix>/creators/<id>/status whenever it changes, and new posts to
+// <prefix>/creators/<id>/content. A post is new while its updated_at matches
+// its created_at, so viral, like-farm and other updates to existing posts are
+// skipped, as are records that do not decode. Statuses count as sent only once
+// the broker acknowledges them, so a failed one is sent again on retry.
+func mqttMessages(cfg *config.Config, tables map[string]sink.TopicTable) func(*kgo.Record) []sink.MQTTMessage {
+	online := make(map[string]bool) // Last acknowledged status by creator
+	return func(record *kgo.Record) []sink.MQTTMessage {
+		if record.Value == nil {
+			return nil
+		}
+
+		var topic string
+		var msg any
+		var published func()
+		retain := false
+		switch tables[record.Topic].EventType {
+		case model.EventCreator:
+			var creator model.Creator
+			if decodePayload(record.Value, &creator) != nil || creator.ID == "" {
+				return nil
+			}
+			if last, ok := online[creator.ID]; ok && last == creator.IsOnline {
+				return nil
+			}
+			published = func() { online[creator.ID] = creator.IsOnline }
+
+			topic = cfg.MQTTTopicPrefix + "/creators/" + mqttTopicLevel(creator.ID) + "/status"
+			msg = creatorStatus{
+				CreatorID: creator.ID,
+				Username:  creator.Username,
+				IsOnline:  creator.IsOnline,
+				ChangedAt: creator.UpdatedAt,
+			}
+			retain = cfg.MQTTRetainStatus
+
+		case model.EventContent:
+			var content model.Content
+			if decodePayload(record.Value, &content) != nil || content.CreatorID == "" {
+				return nil
+			}
+			if content.IsViral || content.UpdatedAt.After(content.CreatedAt) {
+				return nil
+			}
+
+			topic = cfg.MQTTTopicPrefix + "/creators/" + mqttTopicLevel(content.CreatorID) + "/content"
+			msg = contentNotification{
+				ContentID:   content.ID,
+				CreatorID:   content.CreatorID,
+				Title:       content.Title,
+				ContentType: content.ContentType,
+				IsLocked:    content.IsLocked,
+				Price:       content.Price,
+				CreatedAt:   content.CreatedAt,
+			}
+			retain = cfg.MQTTRetainContent
+
+		default:
+			return nil
+		}
+
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			return nil
+		}
+		return []sink.MQTTMessage{{Topic: topic, Payload: payload, Retain: retain, Published: published}}
+	}
+}
+
+// mqttTopicLevel replaces characters that MQTT gives a meaning in topics
+func mqttTopicLevel(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '+', '#', 0:
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	natstest "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"onlyfans-event-publisher/internal/config"
+	"onlyfans-event-publisher/internal/model"
+	"onlyfans-event-publisher/internal/publisher"
+	"onlyfans-event-publisher/internal/sink"
+)
+
+func loadTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	return cfg
+}
+
+func jsonRecord(t *testing.T, topic, key string, event any) *kgo.Record {
+	t.Helper()
+	value, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("marshal %T: %v", event, err)
+	}
+	return &kgo.Record{Topic: topic, Key: []byte(key), Value: value}
+}
+
+func TestNATSSubjects(t *testing.T) {
+	opts := natstest.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	srv := natstest.RunServer(&opts)
+	defer srv.Shutdown()
+
+	cfg := loadTestConfig(t)
+	cfg.NATSURL = srv.ClientURL()
+	cfg.NATSCreateStream = true
+	creators := []model.Creator{{ID: "creator-1", Category: "fitness"}}
+
+	ctx := context.Background()
+	s, err := newNamedSink(ctx, cfg, config.SinkNATS, creators, topicTables(cfg, nil))
+	if err != nil {
+		t.Fatalf("newNamedSink: %v", err)
+	}
+	defer s.Close()
+
+	sequencer := model.NewSequencer("test")
+	wrapped, err := sequencer.Wrap(model.EventContent, "content-2", model.Content{ID: "content-2", CreatorID: "creator-1"})
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+
+	records := []*kgo.Record{
+		jsonRecord(t, cfg.ContentTopic, "content-1", model.Content{ID: "content-1", CreatorID: "creator-1"}),
+		jsonRecord(t, cfg.ContentTopic, "content-2", wrapped),
+		jsonRecord(t, cfg.ContentTopic, "content-3", model.Content{ID: "content-3", CreatorID: "creator-9"}),
+		jsonRecord(t, cfg.CreatorTopic, "creator-1", model.Creator{ID: "creator-1"}),
+		jsonRecord(t, cfg.CreatorTopic, "creator.2 >", model.Creator{ID: "creator.2 >"}),
+		jsonRecord(t, cfg.LiveTopic, "live-1", model.LiveEvent{SessionID: "live-1"}),
+		{Topic: cfg.ContentTopic, Key: []byte("content-1")}, // Tombstones are not published
+		{Topic: "unknown", Key: []byte("x"), Value: []byte(`{}`)},
+	}
+	want := []string{
+		cfg.NATSSubjectPrefix + ".content.fitness",
+		cfg.NATSSubjectPrefix + ".content.fitness",
+		cfg.NATSSubjectPrefix + ".content.unknown",
+		cfg.NATSSubjectPrefix + ".creator.creator-1",
+		cfg.NATSSubjectPrefix + ".creator.creator_2__",
+		cfg.NATSSubjectPrefix + "." + model.EventLive,
+	}
+
+	if err := s.Write(ctx, records); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	// Republishing a batch must not store it twice
+	if err := s.Write(ctx, records); err != nil {
+		t.Fatalf("second Write: %v", err)
+	}
+
+	if stats := s.(*sink.NATS).Stats(); stats.Published != int64(2*len(want)) || stats.Duplicates != int64(len(want)) {
+		t.Errorf("stats = %+v, want %d published with %d duplicates", stats, 2*len(want), len(want))
+	}
+
+	conn, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatalf("jetstream.New: %v", err)
+	}
+	stream, err := js.Stream(ctx, cfg.NATSStream)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.State.Msgs != uint64(len(want)) {
+		t.Fatalf("stream holds %d messages, want %d", info.State.Msgs, len(want))
+	}
+	for i, subject := range want {
+		msg, err := stream.GetMsg(ctx, uint64(i+1))
+		if err != nil {
+			t.Fatalf("GetMsg %d: %v", i+1, err)
+		}
+		if msg.Subject != subject {
+			t.Errorf("message %d subject = %q, want %q", i+1, msg.Subject, subject)
+		}
+	}
+}
+
+func TestMQTTMessages(t *testing.T) {
+	cfg := loadTestConfig(t)
+	messages := mqttMessages(cfg, topicTables(cfg, nil))
+
+	status := func(online bool) *kgo.Record {
+		return jsonRecord(t, cfg.CreatorTopic, "creator-1", model.Creator{ID: "creator-1", IsOnline: online})
+	}
+	statusTopic := cfg.MQTTTopicPrefix + "/creators/creator-1/status"
+
+	msgs := messages(status(true))
+	if len(msgs) != 1 || msgs[0].Topic != statusTopic || msgs[0].Retain != cfg.MQTTRetainStatus {
+		t.Fatalf("first status = %+v", msgs)
+	}
+
+	// Until the broker acknowledges it, the status is sent again
+	if msgs = messages(status(true)); len(msgs) != 1 {
+		t.Fatalf("unacknowledged status not resent: %+v", msgs)
+	}
+	msgs[0].Published()
+	if msgs = messages(status(true)); len(msgs) != 0 {
+		t.Errorf("unchanged status sent again: %+v", msgs)
+	}
+	if msgs = messages(status(false)); len(msgs) != 1 {
+		t.Errorf("changed status not sent: %+v", msgs)
+	}
+
+	now := time.Now()
+	post := model.Content{ID: "content-1", CreatorID: "creator-1", CreatedAt: now, UpdatedAt: now}
+	msgs = messages(jsonRecord(t, cfg.ContentTopic, post.ID, post))
+	if len(msgs) != 1 || msgs[0].Topic != cfg.MQTTTopicPrefix+"/creators/creator-1/content" {
+		t.Fatalf("new post = %+v", msgs)
+	}
+
+	updated := post
+	updated.UpdatedAt = now.Add(time.Minute)
+	updated.LikeCount = 500
+	if msgs = messages(jsonRecord(t, cfg.ContentTopic, post.ID, updated)); len(msgs) != 0 {
+		t.Errorf("updated post sent as new: %+v", msgs)
+	}
+	viral := post
+	viral.IsViral = true
+	if msgs = messages(jsonRecord(t, cfg.ContentTopic, post.ID, viral)); len(msgs) != 0 {
+		t.Errorf("viral update sent as new: %+v", msgs)
+	}
+	if msgs = messages(&kgo.Record{Topic: cfg.ContentTopic, Key: []byte(post.ID)}); len(msgs) != 0 {
+		t.Errorf("tombstone sent: %+v", msgs)
+	}
+}
+
+func TestStdoutSinkNeedsNoBroker(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.RedpandaBrokers = "127.0.0.1:1" // Nothing listens here
+
+	s, err := newNamedSink(context.Background(), cfg, config.SinkStdout, nil, topicTables(cfg, nil))
+	if err != nil {
+		t.Fatalf("newNamedSink: %v", err)
+	}
+	defer s.Close()
+	if _, ok := s.(*sink.Stdout); !ok {
+		t.Errorf("stdout sink is a %T", s)
+	}
+}
+
+func TestPartitionerHashesIDsFromValues(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Partitioning = map[string]string{
+		cfg.ContentTopic: sink.PartitionCreatorID,
+		cfg.CreatorTopic: sink.PartitionCreatorID,
+		cfg.CommentTopic: sink.PartitionContentID,
+	}
+	partitioner, err := newPartitioner(cfg, nil, topicTables(cfg, nil))
+	if err != nil {
+		t.Fatalf("newPartitioner: %v", err)
+	}
+	partition := func(record *kgo.Record) int {
+		return partitioner.ForTopic(record.Topic).Partition(record, 64)
+	}
+
+	wrapped, err := model.NewSequencer("test").Wrap(model.EventContent, "content-2", model.Content{ID: "content-2", CreatorID: "creator-1"})
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	tombstone := &kgo.Record{
+		Topic:   cfg.ContentTopic,
+		Key:     []byte("content-1"),
+		Headers: []kgo.RecordHeader{{Key: publisher.CreatorIDHeader, Value: []byte("creator-1")}},
+	}
+
+	// A creator's profile, posts and removals share a partition
+	want := partition(jsonRecord(t, cfg.CreatorTopic, "creator-1", model.Creator{ID: "creator-1"}))
+	for _, record := range []*kgo.Record{
+		jsonRecord(t, cfg.ContentTopic, "content-1", model.Content{ID: "content-1", CreatorID: "creator-1"}),
+		jsonRecord(t, cfg.ContentTopic, "content-2", wrapped),
+		tombstone,
+	} {
+		if got := partition(record); got != want {
+			t.Errorf("%s record %s on partition %d, want %d", record.Topic, record.Key, got, want)
+		}
+	}
+	if string(tombstone.Key) != "content-1" {
+		t.Errorf("tombstone key = %s, want the content ID", tombstone.Key)
+	}
+
+	// Other creators spread over other partitions
+	spread := map[int]bool{}
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("creator-%d", i)
+		spread[partition(jsonRecord(t, cfg.ContentTopic, "content-1", model.Content{ID: "content-1", CreatorID: id}))] = true
+	}
+	if len(spread) < 2 {
+		t.Errorf("ten creators' content on %d partition", len(spread))
+	}
+
+	// Comments follow the content they belong to
+	comment := jsonRecord(t, cfg.CommentTopic, "comment-1", model.Comment{ID: "comment-1", ContentID: "content-1", CreatorID: "creator-9"})
+	other := jsonRecord(t, cfg.CommentTopic, "comment-2", model.Comment{ID: "comment-2", ContentID: "content-1", CreatorID: "creator-1"})
+	if partition(comment) != partition(other) {
+		t.Error("comments on one post on different partitions")
+	}
+}
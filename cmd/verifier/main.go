@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"onlyfans-event-publisher/internal/config"
+	"onlyfans-event-publisher/internal/model"
+	"onlyfans-event-publisher/internal/publisher"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Verifier reads enveloped events from every publisher topic and reports
+// sequence gaps, late arrivals and duplicates per producer, event type and key
+func main() {
+	log.Println("Starting Event Verifier...")
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	topics := []string{
+		cfg.ContentTopic, cfg.CreatorTopic, cfg.LiveTopic, cfg.CommentTopic, cfg.MessageTopic,
+		cfg.ReportTopic, cfg.ModerationTopic, cfg.TxTopic, cfg.PayoutTopic, cfg.BalanceTopic,
+		cfg.FraudTopic, cfg.PromotionTopic, cfg.FxTopic,
+	}
+	if cfg.TopicRoutesFile != "" {
+		router, err := publisher.LoadRouter(cfg.TopicRoutesFile)
+		if err != nil {
+			log.Fatalf("Invalid topic routes: %v", err)
+		}
+		var routed []string
+		for topic := range router.Topics() {
+			if !slices.Contains(topics, topic) {
+				routed = append(routed, topic)
+			}
+		}
+		slices.Sort(routed)
+		topics = append(topics, routed...)
+	}
+	log.Printf("  Redpanda Brokers: %s", cfg.RedpandaBrokers)
+	log.Printf("  Topics: %s", strings.Join(topics, ", "))
+
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(strings.Split(cfg.RedpandaBrokers, ",")...),
+		kgo.ConsumeTopics(topics...),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create Redpanda client: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	checker := model.NewSequenceChecker(true)
+	stats := &Statistics{}
+	report := time.NewTicker(10 * time.Second)
+	defer report.Stop()
+
+	for {
+		fetches := client.PollFetches(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		fetches.EachError(func(topic string, partition int32, err error) {
+			log.Printf("Fetch error on %s/%d: %v", topic, partition, err)
+		})
+
+		fetches.EachRecord(func(record *kgo.Record) {
+			stats.check(checker, record)
+		})
+
+		select {
+		case <-report.C:
+			stats.print(checker)
+		default:
+		}
+	}
+
+	log.Println("Received shutdown signal")
+	stats.print(checker)
+	// Gaps filled by late events are fine; only events never seen fail
+	if stats.Duplicates > 0 || checker.Outstanding() > 0 {
+		os.Exit(1)
+	}
+}
+
+// Statistics counts sequence check results
+type Statistics struct {
+	Records    int64
+	Tombstones int64
+	Unwrapped  int64 // Values that aren't envelopes
+	Gaps       int64
+	Missing    uint64 // Events skipped by gaps
+	Late       int64
+	Duplicates int64
+}
+
+// check decodes a record's envelope and records how it fits its stream
+func (s *Statistics) check(checker *model.SequenceChecker, record *kgo.Record) {
+	s.Records++
+	if record.Value == nil {
+		s.Tombstones++
+		return
+	}
+
+	var envelope model.Envelope
+	if err := json.Unmarshal(record.Value, &envelope); err != nil || envelope.EventID == "" {
+		s.Unwrapped++
+		return
+	}
+
+	result := checker.Check(envelope)
+	switch result.Status {
+	case model.SequenceGap:
+		s.Gaps++
+		s.Missing += result.Missing
+		log.Printf("Gap on %s: %s %s missing %d before sequence %d (producer %s)",
+			record.Topic, envelope.EventType, envelope.Key, result.Missing, envelope.Sequence, envelope.ProducerID)
+	case model.SequenceLate:
+		s.Late++
+	case model.SequenceDuplicate:
+		s.Duplicates++
+		log.Printf("Duplicate on %s: %s %s sequence %d, event %s (producer %s)",
+			record.Topic, envelope.EventType, envelope.Key, envelope.Sequence, envelope.EventID, envelope.ProducerID)
+	}
+}
+
+// print logs a summary of the checks so far
+func (s *Statistics) print(checker *model.SequenceChecker) {
+	log.Printf("Verified %d records across %d streams: %d gaps (%d events, %d still missing), %d late, %d duplicates, %d tombstones, %d unwrapped",
+		s.Records, checker.Streams(), s.Gaps, s.Missing, checker.Outstanding(), s.Late, s.Duplicates, s.Tombstones, s.Unwrapped)
+}
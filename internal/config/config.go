@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds the application configuration
@@ -12,22 +13,237 @@ type Config struct {
 	RedpandaBrokers string
 	ContentTopic    string
 	CreatorTopic    string
+	LiveTopic       string
+	CommentTopic    string
+	MessageTopic    string
+	ReportTopic     string
+	ModerationTopic string
+	TxTopic         string
+	PayoutTopic     string
+	BalanceTopic    string
+	FraudTopic      string
+	PromotionTopic  string
+	FxTopic         string
+	TopicRoutesFile string            // JSON routing rules; empty sends every event to its configured topic
+	Partitioning    map[string]string // Strategy by topic; topics not listed are partitioned by key
+
+	// Output sinks, each record is written to all of them; the settings that
+	// follow Sinks only apply to their sinks
+	Sinks                  []string
+	SinkPolicies           map[string]string // By sink; fail-fast when not listed
+	SinkRetryBuffer        int               // Records each buffer-and-retry sink holds
+	MirrorRedpandaBrokers  string            // Second cluster the kafka-mirror sink produces to
+	StdoutSinkFormat       string
+	FileSinkDir            string
+	FileSinkMaxBytes       int
+	FileSinkMaxAgeMs       int // 0 disables age-based rotation
+	FileSinkCompression    string
+	ParquetSinkDir         string
+	ParquetSinkMaxRows     int
+	ParquetSinkMaxAgeMs    int // 0 disables age-based rotation
+	ParquetSinkCompression string
+	WebhookURLs            string // "EVENT_TYPE=URL,..."; "*" matches any other event type
+	WebhookSecret          string
+	WebhookBatchSize       int
+	WebhookTimeoutMs       int
+	WebhookMaxRetries      int
+	WebhookBackoffMs       int
+	WebhookDeadLetterFile  string
+	NATSURL                string
+	NATSStream             string
+	NATSCreateStream       bool
+	NATSSubjectPrefix      string
+	NATSDuplicateWindowMs  int
+	NATSAckTimeoutMs       int
+	MQTTBroker             string
+	MQTTClientID           string
+	MQTTTopicPrefix        string
+	MQTTQoS                int
+	MQTTRetainStatus       bool
+	MQTTRetainContent      bool
+	MQTTPublishTimeoutMs   int
+	RedisURL               string
+	RedisStreamPrefix      string
+	RedisMaxLen            int
+	RedisExactMaxLen       bool
 
 	// Simulation configuration
-	NumCreators         int
-	IntervalMs          int
-	AbnormalProbability float64
+	NumCreators          int
+	IntervalMs           int
+	AbnormalProbability  float64
+	ViralProbability     float64
+	LiveProbability      float64
+	CommentProbability   float64
+	MessageProbability   float64
+	ReportProbability    float64
+	PromotionProbability float64
+
+	// Currency configuration; country weights are "COUNTRY:WEIGHT,..." lists
+	CreatorCountryWeights  string
+	FanCountryWeights      string
+	ExchangeRateIntervalMs int
+	LegacyFloatMoney       bool // Also emit amounts as the pre-Money float fields
+
+	// Validation; invalid events are only counted unless RejectTopic is set
+	ValidateEvents bool
+	RejectTopic    string
+
+	// Chaos payloads; an empty ChaosKinds enables every kind
+	ChaosRate  float64
+	ChaosKinds string
+
+	// Disorder: late, reordered and duplicate records
+	LateRate      float64
+	MaxLatenessMs int
+	ReorderWindow int
+	DuplicateRate float64
+
+	// Event envelopes; an empty ProducerID generates one per run
+	EventEnvelope bool
+	ProducerID    string
+
+	// Earnings configuration
+	PlatformFee      float64
+	PayoutIntervalMs int
+	RefundRate       float64
+	ChargebackRate   float64
+	FraudFanFraction float64
+
+	// Fraud injection rates, per cycle
+	FraudCardTestingRate     float64
+	FraudLikeFarmRate        float64
+	FraudAccountTakeoverRate float64
+	FraudCollusionRingRate   float64
+
+	// Creator popularity distributions, as "kind:key=value,..." specs
+	SubscriberDistribution string
+	ActivityDistribution   string
+	EngagementDistribution string
 }
 
+// Supported sinks
+const (
+	SinkKafka   = "kafka"
+	SinkMirror  = "kafka-mirror"
+	SinkFile    = "file"
+	SinkParquet = "parquet"
+	SinkStdout  = "stdout"
+	SinkWebhook = "webhook"
+	SinkNATS    = "nats"
+	SinkMQTT    = "mqtt"
+	SinkRedis   = "redis"
+)
+
+// Sinks lists every supported sink
+var Sinks = []string{SinkKafka, SinkMirror, SinkFile, SinkParquet, SinkStdout, SinkWebhook, SinkNATS, SinkMQTT, SinkRedis}
+
+// defaultCountryWeights roughly follows where creators and fans come from
+const defaultCountryWeights = "US:40,GB:12,CA:8,DE:8,FR:6,ES:5,AU:5,BR:4,IT:4,NL:3,MX:2,JP:2,IN:1"
+
 // Load loads configuration from environment variables with fallbacks
 func Load() (*Config, error) {
 	config := &Config{
-		RedpandaBrokers:     getEnv("REDPANDA_BROKERS", "redpanda-1:9092,redpanda-2:9092"),
-		ContentTopic:        getEnv("CONTENT_TOPIC", "content"),
-		CreatorTopic:        getEnv("CREATOR_TOPIC", "creator"),
-		NumCreators:         getEnvAsInt("NUM_CREATORS", 10),
-		IntervalMs:          getEnvAsInt("INTERVAL_MS", 1000),
-		AbnormalProbability: getEnvAsFloat("ABNORMAL_PROBABILITY", 0.8),
+		RedpandaBrokers: getEnv("REDPANDA_BROKERS", "redpanda-1:9092,redpanda-2:9092"),
+		ContentTopic:    getEnv("CONTENT_TOPIC", "content"),
+		CreatorTopic:    getEnv("CREATOR_TOPIC", "creator"),
+		LiveTopic:       getEnv("LIVE_TOPIC", "live"),
+		CommentTopic:    getEnv("COMMENT_TOPIC", "comments"),
+		MessageTopic:    getEnv("MESSAGE_TOPIC", "messages"),
+		ReportTopic:     getEnv("REPORT_TOPIC", "reports"),
+		ModerationTopic: getEnv("MODERATION_TOPIC", "moderation"),
+		TxTopic:         getEnv("TRANSACTION_TOPIC", "transactions"),
+		PayoutTopic:     getEnv("PAYOUT_TOPIC", "payouts"),
+		BalanceTopic:    getEnv("BALANCE_TOPIC", "balances"),
+		FraudTopic:      getEnv("FRAUD_LABEL_TOPIC", "fraud-labels"),
+		PromotionTopic:  getEnv("PROMOTION_TOPIC", "promotions"),
+		FxTopic:         getEnv("EXCHANGE_RATE_TOPIC", "exchange-rates"),
+		TopicRoutesFile: getEnv("TOPIC_ROUTES_FILE", ""),
+
+		Sinks:                 getEnvAsList("SINK", SinkKafka),
+		SinkRetryBuffer:       getEnvAsInt("SINK_RETRY_BUFFER", 10000),
+		MirrorRedpandaBrokers: getEnv("MIRROR_REDPANDA_BROKERS", ""),
+		StdoutSinkFormat:      getEnv("STDOUT_SINK_FORMAT", "pretty"),
+		FileSinkDir:           getEnv("FILE_SINK_DIR", "data"),
+		FileSinkMaxBytes:      getEnvAsInt("FILE_SINK_MAX_BYTES", 100*1024*1024),
+		FileSinkMaxAgeMs:      getEnvAsInt("FILE_SINK_MAX_AGE_MS", 3600000),
+		FileSinkCompression:   getEnv("FILE_SINK_COMPRESSION", "none"),
+
+		ParquetSinkDir:         getEnv("PARQUET_SINK_DIR", "data/parquet"),
+		ParquetSinkMaxRows:     getEnvAsInt("PARQUET_SINK_MAX_ROWS", 100000),
+		ParquetSinkMaxAgeMs:    getEnvAsInt("PARQUET_SINK_MAX_AGE_MS", 3600000),
+		ParquetSinkCompression: getEnv("PARQUET_SINK_COMPRESSION", "snappy"),
+
+		WebhookURLs:           getEnv("WEBHOOK_URLS", ""),
+		WebhookSecret:         getEnv("WEBHOOK_SECRET", ""),
+		WebhookBatchSize:      getEnvAsInt("WEBHOOK_BATCH_SIZE", 1),
+		WebhookTimeoutMs:      getEnvAsInt("WEBHOOK_TIMEOUT_MS", 5000),
+		WebhookMaxRetries:     getEnvAsInt("WEBHOOK_MAX_RETRIES", 5),
+		WebhookBackoffMs:      getEnvAsInt("WEBHOOK_BACKOFF_MS", 500),
+		WebhookDeadLetterFile: getEnv("WEBHOOK_DEAD_LETTER_FILE", "webhook-dead-letters.ndjson"),
+
+		NATSURL:               getEnv("NATS_URL", "nats://localhost:4222"),
+		NATSStream:            getEnv("NATS_STREAM", "PLATFORM"),
+		NATSCreateStream:      getEnvAsBool("NATS_CREATE_STREAM", true),
+		NATSSubjectPrefix:     getEnv("NATS_SUBJECT_PREFIX", "platform"),
+		NATSDuplicateWindowMs: getEnvAsInt("NATS_DUPLICATE_WINDOW_MS", 120000),
+		NATSAckTimeoutMs:      getEnvAsInt("NATS_ACK_TIMEOUT_MS", 5000),
+
+		MQTTBroker:           getEnv("MQTT_BROKER", "tcp://localhost:1883"),
+		MQTTClientID:         getEnv("MQTT_CLIENT_ID", "onlyfans-event-publisher"),
+		MQTTTopicPrefix:      getEnv("MQTT_TOPIC_PREFIX", "platform"),
+		MQTTQoS:              getEnvAsInt("MQTT_QOS", 1),
+		MQTTRetainStatus:     getEnvAsBool("MQTT_RETAIN_STATUS", true),
+		MQTTRetainContent:    getEnvAsBool("MQTT_RETAIN_CONTENT", false),
+		MQTTPublishTimeoutMs: getEnvAsInt("MQTT_PUBLISH_TIMEOUT_MS", 5000),
+
+		RedisURL:          getEnv("REDIS_URL", "redis://localhost:6379/0"),
+		RedisStreamPrefix: getEnv("REDIS_STREAM_PREFIX", ""),
+		RedisMaxLen:       getEnvAsInt("REDIS_MAXLEN", 0),
+		RedisExactMaxLen:  getEnvAsBool("REDIS_EXACT_MAXLEN", false),
+
+		NumCreators:          getEnvAsInt("NUM_CREATORS", 10),
+		IntervalMs:           getEnvAsInt("INTERVAL_MS", 1000),
+		AbnormalProbability:  getEnvAsFloat("ABNORMAL_PROBABILITY", 0.8),
+		ViralProbability:     getEnvAsFloat("VIRAL_PROBABILITY", 0.02),
+		LiveProbability:      getEnvAsFloat("LIVE_PROBABILITY", 0.02),
+		CommentProbability:   getEnvAsFloat("COMMENT_PROBABILITY", 0.05),
+		MessageProbability:   getEnvAsFloat("MESSAGE_PROBABILITY", 0.1),
+		ReportProbability:    getEnvAsFloat("REPORT_PROBABILITY", 0.005),
+		PromotionProbability: getEnvAsFloat("PROMOTION_PROBABILITY", 0.01),
+
+		CreatorCountryWeights:  getEnv("CREATOR_COUNTRY_WEIGHTS", defaultCountryWeights),
+		FanCountryWeights:      getEnv("FAN_COUNTRY_WEIGHTS", defaultCountryWeights),
+		ExchangeRateIntervalMs: getEnvAsInt("EXCHANGE_RATE_INTERVAL_MS", 60000),
+		LegacyFloatMoney:       getEnvAsBool("LEGACY_FLOAT_MONEY", true),
+
+		ValidateEvents: getEnvAsBool("VALIDATE_EVENTS", false),
+		RejectTopic:    getEnv("REJECT_TOPIC", ""),
+
+		ChaosRate:  getEnvAsFloat("CHAOS_RATE", 0),
+		ChaosKinds: getEnv("CHAOS_KINDS", ""),
+
+		LateRate:      getEnvAsFloat("LATE_RATE", 0),
+		MaxLatenessMs: getEnvAsInt("MAX_LATENESS_MS", 30000),
+		ReorderWindow: getEnvAsInt("REORDER_WINDOW", 0),
+		DuplicateRate: getEnvAsFloat("DUPLICATE_RATE", 0),
+
+		EventEnvelope: getEnvAsBool("EVENT_ENVELOPE", false),
+		ProducerID:    getEnv("PRODUCER_ID", ""),
+
+		PlatformFee:      getEnvAsFloat("PLATFORM_FEE", 0.2),
+		PayoutIntervalMs: getEnvAsInt("PAYOUT_INTERVAL_MS", 60000),
+		RefundRate:       getEnvAsFloat("REFUND_RATE", 0.02),
+		ChargebackRate:   getEnvAsFloat("CHARGEBACK_RATE", 0.005),
+		FraudFanFraction: getEnvAsFloat("FRAUD_FAN_FRACTION", 0.01),
+
+		FraudCardTestingRate:     getEnvAsFloat("FRAUD_CARD_TESTING_RATE", 0.005),
+		FraudLikeFarmRate:        getEnvAsFloat("FRAUD_LIKE_FARM_RATE", 0.005),
+		FraudAccountTakeoverRate: getEnvAsFloat("FRAUD_ACCOUNT_TAKEOVER_RATE", 0.001),
+		FraudCollusionRingRate:   getEnvAsFloat("FRAUD_COLLUSION_RING_RATE", 0.002),
+
+		SubscriberDistribution: getEnv("SUBSCRIBER_DISTRIBUTION", "uniform:min=100,max=10100"),
+		ActivityDistribution:   getEnv("ACTIVITY_DISTRIBUTION", "uniform:min=0.2,max=1.0"),
+		EngagementDistribution: getEnv("ENGAGEMENT_DISTRIBUTION", "uniform:min=0.05,max=0.2"),
 	}
 
 	// Validate configuration
@@ -43,6 +259,114 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("ABNORMAL_PROBABILITY must be between 0 and 1")
 	}
 
+	if config.ViralProbability < 0 || config.ViralProbability > 1 {
+		return nil, fmt.Errorf("VIRAL_PROBABILITY must be between 0 and 1")
+	}
+
+	if config.LiveProbability < 0 || config.LiveProbability > 1 {
+		return nil, fmt.Errorf("LIVE_PROBABILITY must be between 0 and 1")
+	}
+
+	if config.CommentProbability < 0 || config.CommentProbability > 1 {
+		return nil, fmt.Errorf("COMMENT_PROBABILITY must be between 0 and 1")
+	}
+
+	if config.MessageProbability < 0 || config.MessageProbability > 1 {
+		return nil, fmt.Errorf("MESSAGE_PROBABILITY must be between 0 and 1")
+	}
+
+	if config.ReportProbability < 0 || config.ReportProbability > 1 {
+		return nil, fmt.Errorf("REPORT_PROBABILITY must be between 0 and 1")
+	}
+
+	if config.PlatformFee < 0 || config.PlatformFee > 1 {
+		return nil, fmt.Errorf("PLATFORM_FEE must be between 0 and 1")
+	}
+
+	if config.PayoutIntervalMs < config.IntervalMs {
+		return nil, fmt.Errorf("PAYOUT_INTERVAL_MS must be at least INTERVAL_MS")
+	}
+
+	if config.RefundRate < 0 || config.RefundRate > 1 {
+		return nil, fmt.Errorf("REFUND_RATE must be between 0 and 1")
+	}
+
+	if config.ChargebackRate < 0 || config.ChargebackRate > 1 {
+		return nil, fmt.Errorf("CHARGEBACK_RATE must be between 0 and 1")
+	}
+
+	if config.FraudFanFraction < 0 || config.FraudFanFraction > 1 {
+		return nil, fmt.Errorf("FRAUD_FAN_FRACTION must be between 0 and 1")
+	}
+
+	for name, rate := range map[string]float64{
+		"FRAUD_CARD_TESTING_RATE":     config.FraudCardTestingRate,
+		"FRAUD_LIKE_FARM_RATE":        config.FraudLikeFarmRate,
+		"FRAUD_ACCOUNT_TAKEOVER_RATE": config.FraudAccountTakeoverRate,
+		"FRAUD_COLLUSION_RING_RATE":   config.FraudCollusionRingRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+
+	if config.PromotionProbability < 0 || config.PromotionProbability > 1 {
+		return nil, fmt.Errorf("PROMOTION_PROBABILITY must be between 0 and 1")
+	}
+
+	if config.ChaosRate < 0 || config.ChaosRate > 1 {
+		return nil, fmt.Errorf("CHAOS_RATE must be between 0 and 1")
+	}
+
+	if config.LateRate < 0 || config.LateRate > 1 {
+		return nil, fmt.Errorf("LATE_RATE must be between 0 and 1")
+	}
+
+	if config.DuplicateRate < 0 || config.DuplicateRate > 1 {
+		return nil, fmt.Errorf("DUPLICATE_RATE must be between 0 and 1")
+	}
+
+	if config.MaxLatenessMs <= 0 {
+		return nil, fmt.Errorf("MAX_LATENESS_MS must be greater than 0")
+	}
+
+	if config.ReorderWindow < 0 {
+		return nil, fmt.Errorf("REORDER_WINDOW cannot be negative")
+	}
+
+	if len(config.Sinks) == 0 {
+		return nil, fmt.Errorf("SINK cannot be empty")
+	}
+	seen := make(map[string]bool)
+	for _, sink := range config.Sinks {
+		if seen[sink] {
+			return nil, fmt.Errorf("SINK lists %s more than once", sink)
+		}
+		seen[sink] = true
+		if err := config.validateSink(sink); err != nil {
+			return nil, err
+		}
+	}
+
+	policies, err := parseSinkPolicies(getEnv("SINK_POLICIES", ""), seen)
+	if err != nil {
+		return nil, fmt.Errorf("SINK_POLICIES: %w", err)
+	}
+	config.SinkPolicies = policies
+	if config.SinkRetryBuffer <= 0 {
+		return nil, fmt.Errorf("SINK_RETRY_BUFFER must be greater than 0")
+	}
+
+	partitioning, err := parsePartitioning(getEnv("PARTITIONING", ""))
+	if err != nil {
+		return nil, fmt.Errorf("PARTITIONING: %w", err)
+	}
+	config.Partitioning = partitioning
+
+	if config.ExchangeRateIntervalMs < config.IntervalMs {
+		return nil, fmt.Errorf("EXCHANGE_RATE_INTERVAL_MS must be at least INTERVAL_MS")
+	}
+
 	if config.ContentTopic == "" {
 		return nil, fmt.Errorf("CONTENT_TOPIC cannot be empty")
 	}
@@ -51,9 +375,185 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("CREATOR_TOPIC cannot be empty")
 	}
 
+	if config.LiveTopic == "" {
+		return nil, fmt.Errorf("LIVE_TOPIC cannot be empty")
+	}
+
+	if config.CommentTopic == "" {
+		return nil, fmt.Errorf("COMMENT_TOPIC cannot be empty")
+	}
+
+	if config.MessageTopic == "" {
+		return nil, fmt.Errorf("MESSAGE_TOPIC cannot be empty")
+	}
+
+	if config.ReportTopic == "" {
+		return nil, fmt.Errorf("REPORT_TOPIC cannot be empty")
+	}
+
+	if config.ModerationTopic == "" {
+		return nil, fmt.Errorf("MODERATION_TOPIC cannot be empty")
+	}
+
+	if config.TxTopic == "" || config.PayoutTopic == "" || config.BalanceTopic == "" {
+		return nil, fmt.Errorf("TRANSACTION_TOPIC, PAYOUT_TOPIC and BALANCE_TOPIC cannot be empty")
+	}
+
+	if config.FraudTopic == "" {
+		return nil, fmt.Errorf("FRAUD_LABEL_TOPIC cannot be empty")
+	}
+
+	if config.PromotionTopic == "" {
+		return nil, fmt.Errorf("PROMOTION_TOPIC cannot be empty")
+	}
+
+	if config.FxTopic == "" {
+		return nil, fmt.Errorf("EXCHANGE_RATE_TOPIC cannot be empty")
+	}
+
 	return config, nil
 }
 
+// validateSink checks the settings of one of the configured sinks
+func (c *Config) validateSink(sink string) error {
+	switch sink {
+	case SinkKafka:
+	case SinkMirror:
+		if c.MirrorRedpandaBrokers == "" {
+			return fmt.Errorf("MIRROR_REDPANDA_BROKERS cannot be empty")
+		}
+	case SinkFile:
+		if c.FileSinkDir == "" {
+			return fmt.Errorf("FILE_SINK_DIR cannot be empty")
+		}
+		if c.FileSinkMaxBytes < 0 || c.FileSinkMaxAgeMs < 0 {
+			return fmt.Errorf("FILE_SINK_MAX_BYTES and FILE_SINK_MAX_AGE_MS cannot be negative")
+		}
+		switch c.FileSinkCompression {
+		case "none", "gzip", "zstd":
+		default:
+			return fmt.Errorf("FILE_SINK_COMPRESSION must be none, gzip or zstd")
+		}
+	case SinkParquet:
+		if c.ParquetSinkDir == "" {
+			return fmt.Errorf("PARQUET_SINK_DIR cannot be empty")
+		}
+		if c.ParquetSinkMaxRows < 0 || c.ParquetSinkMaxAgeMs < 0 {
+			return fmt.Errorf("PARQUET_SINK_MAX_ROWS and PARQUET_SINK_MAX_AGE_MS cannot be negative")
+		}
+		switch c.ParquetSinkCompression {
+		case "none", "snappy", "gzip", "zstd":
+		default:
+			return fmt.Errorf("PARQUET_SINK_COMPRESSION must be none, snappy, gzip or zstd")
+		}
+	case SinkStdout:
+		if c.StdoutSinkFormat != "pretty" && c.StdoutSinkFormat != "compact" {
+			return fmt.Errorf("STDOUT_SINK_FORMAT must be pretty or compact")
+		}
+	case SinkNATS:
+		if c.NATSURL == "" || c.NATSStream == "" || c.NATSSubjectPrefix == "" {
+			return fmt.Errorf("NATS_URL, NATS_STREAM and NATS_SUBJECT_PREFIX cannot be empty")
+		}
+		if c.NATSDuplicateWindowMs < 0 || c.NATSAckTimeoutMs <= 0 {
+			return fmt.Errorf("NATS_DUPLICATE_WINDOW_MS cannot be negative and NATS_ACK_TIMEOUT_MS must be greater than 0")
+		}
+	case SinkWebhook:
+		if c.WebhookURLs == "" {
+			return fmt.Errorf("WEBHOOK_URLS cannot be empty")
+		}
+		if c.WebhookBatchSize < 1 {
+			return fmt.Errorf("WEBHOOK_BATCH_SIZE must be at least 1")
+		}
+		if c.WebhookTimeoutMs <= 0 {
+			return fmt.Errorf("WEBHOOK_TIMEOUT_MS must be greater than 0")
+		}
+		if c.WebhookMaxRetries < 0 || c.WebhookBackoffMs < 0 {
+			return fmt.Errorf("WEBHOOK_MAX_RETRIES and WEBHOOK_BACKOFF_MS cannot be negative")
+		}
+		if c.WebhookDeadLetterFile == "" {
+			return fmt.Errorf("WEBHOOK_DEAD_LETTER_FILE cannot be empty")
+		}
+	case SinkMQTT:
+		if c.MQTTBroker == "" || c.MQTTTopicPrefix == "" {
+			return fmt.Errorf("MQTT_BROKER and MQTT_TOPIC_PREFIX cannot be empty")
+		}
+		if c.MQTTQoS < 0 || c.MQTTQoS > 2 {
+			return fmt.Errorf("MQTT_QOS must be 0, 1 or 2")
+		}
+		if c.MQTTPublishTimeoutMs <= 0 {
+			return fmt.Errorf("MQTT_PUBLISH_TIMEOUT_MS must be greater than 0")
+		}
+	case SinkRedis:
+		if c.RedisURL == "" {
+			return fmt.Errorf("REDIS_URL cannot be empty")
+		}
+		if c.RedisMaxLen < 0 {
+			return fmt.Errorf("REDIS_MAXLEN cannot be negative")
+		}
+	default:
+		return fmt.Errorf("unknown sink %q in SINK (expected %s)", sink, strings.Join(Sinks, ", "))
+	}
+	return nil
+}
+
+// parseSinkPolicies parses SINK=POLICY pairs separated by commas, checking
+// that each sink is configured
+func parseSinkPolicies(s string, sinks map[string]bool) (map[string]string, error) {
+	policies, err := parsePairs(s, "SINK=POLICY")
+	if err != nil {
+		return nil, err
+	}
+	for sink, policy := range policies {
+		if !sinks[sink] {
+			return nil, fmt.Errorf("%s is not listed in SINK", sink)
+		}
+		switch policy {
+		case "fail-fast", "best-effort", "buffer-and-retry":
+		default:
+			return nil, fmt.Errorf("unknown policy %q for %s (expected fail-fast, best-effort or buffer-and-retry)", policy, sink)
+		}
+	}
+	return policies, nil
+}
+
+// parsePartitioning parses TOPIC=STRATEGY pairs separated by commas
+func parsePartitioning(s string) (map[string]string, error) {
+	strategies, err := parsePairs(s, "TOPIC=STRATEGY")
+	if err != nil {
+		return nil, err
+	}
+	for topic, strategy := range strategies {
+		switch strategy {
+		case "key", "creator_id", "content_id", "round_robin", "sticky", "category":
+		default:
+			return nil, fmt.Errorf("unknown strategy %q for %s (expected key, creator_id, content_id, round_robin, sticky or category)", strategy, topic)
+		}
+	}
+	return strategies, nil
+}
+
+// parsePairs parses NAME=VALUE pairs separated by commas; form describes a
+// pair in errors
+func parsePairs(s, form string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected %s", pair, form)
+		}
+		if _, dup := pairs[name]; dup {
+			return nil, fmt.Errorf("%s listed more than once", name)
+		}
+		pairs[name] = value
+	}
+	return pairs, nil
+}
+
 // getEnv gets an environment variable with a fallback value
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -62,6 +562,18 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvAsList gets a comma-separated environment variable as a list with a
+// fallback value; blank entries are dropped
+func getEnvAsList(key string, fallback string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // getEnvAsInt gets an environment variable as an integer with a fallback value
 func getEnvAsInt(key string, fallback int) int {
 	if value, exists := os.LookupEnv(key); exists {
@@ -72,6 +584,16 @@ func getEnvAsInt(key string, fallback int) int {
 	return fallback
 }
 
+// getEnvAsBool gets an environment variable as a boolean with a fallback value
+func getEnvAsBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
 // getEnvAsFloat gets an environment variable as a float with a fallback value
 func getEnvAsFloat(key string, fallback float64) float64 {
 	if value, exists := os.LookupEnv(key); exists {
@@ -10,15 +10,19 @@ type Content struct {
 	Description string    `json:"description,omitempty"`
 	ContentType string    `json:"content_type"` // "image", "video", "text", "live"
 	MediaURL    string    `json:"media_url,omitempty"`
-	Price       float64   `json:"price"`     // 0 for free content
-	IsLocked    bool      `json:"is_locked"` // Premium content requiring payment
+	Price       Money     `json:"price_money"` // Zero for free content
+	IsLocked    bool      `json:"is_locked"`   // Premium content requiring payment
 	ViewCount   int       `json:"view_count"`
 	LikeCount   int       `json:"like_count"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	Tags        []string  `json:"tags,omitempty"`
+	IsViral     bool      `json:"is_viral,omitempty"` // Set on updates while a post is going viral
 }
 
+// TagViral is added to the tags of content that has gone viral
+const TagViral = "viral"
+
 // Content types for simulation
 var ContentTypes = []string{
 	"image",
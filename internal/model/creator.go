@@ -4,17 +4,23 @@ import "time"
 
 // Creator represents a content creator on the platform
 type Creator struct {
-	ID              string    `json:"id"`
-	Username        string    `json:"username"`
-	DisplayName     string    `json:"display_name"`
-	Email           string    `json:"email"`
-	IsVerified      bool      `json:"is_verified"`
-	SubscriberCount int       `json:"subscriber_count"`
-	MonthlyPrice    float64   `json:"monthly_price"`
-	CreatedAt       time.Time `json:"created_at"`
-	IsOnline        bool      `json:"is_online"`
-	Category        string    `json:"category"`
-	ProfilePic      string    `json:"profile_pic,omitempty"`
+	ID              string     `json:"id"`
+	Username        string     `json:"username"`
+	DisplayName     string     `json:"display_name"`
+	Email           string     `json:"email"`
+	IsVerified      bool       `json:"is_verified"`
+	SubscriberCount int        `json:"subscriber_count"`
+	MonthlyPrice    Money      `json:"monthly_price_money"`
+	Currency        string     `json:"currency"`
+	Country         string     `json:"country"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"` // When this profile version was published
+	IsOnline        bool       `json:"is_online"`
+	Category        string     `json:"category"`
+	ProfilePic      string     `json:"profile_pic,omitempty"`
+	IsSuspended     bool       `json:"is_suspended,omitempty"`
+	Promotion       *Promotion `json:"promotion,omitempty"` // Active promotion, if any
+	Bundles         []Bundle   `json:"bundles,omitempty"`   // Multi-month offers
 }
 
 // Creator categories for simulation
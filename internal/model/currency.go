@@ -0,0 +1,58 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// BaseCurrency is the currency exchange rates are quoted against
+const BaseCurrency = "USD"
+
+// CountryCurrencies maps supported countries to their currency
+var CountryCurrencies = map[string]string{
+	"US": "USD",
+	"CA": "CAD",
+	"MX": "MXN",
+	"BR": "BRL",
+	"GB": "GBP",
+	"DE": "EUR",
+	"FR": "EUR",
+	"ES": "EUR",
+	"IT": "EUR",
+	"NL": "EUR",
+	"AU": "AUD",
+	"JP": "JPY",
+	"IN": "INR",
+}
+
+// currencyExponents holds the number of minor-unit digits for currencies
+// that don't use two
+var currencyExponents = map[string]int{
+	"JPY": 0,
+}
+
+// ExchangeRateSnapshot holds the value of one BaseCurrency unit in each currency
+type ExchangeRateSnapshot struct {
+	ID    string             `json:"id"`
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+	AsOf  time.Time          `json:"as_of"`
+}
+
+// CurrencyExponent returns the number of decimal places used by a currency
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// ToMinor converts an amount to integer minor units (e.g. cents) of the currency
+func ToMinor(amount float64, currency string) int64 {
+	return int64(math.Round(amount * math.Pow10(CurrencyExponent(currency))))
+}
+
+// FromMinor converts integer minor units of the currency back to an amount
+func FromMinor(minor int64, currency string) float64 {
+	return float64(minor) / math.Pow10(CurrencyExponent(currency))
+}
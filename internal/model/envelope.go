@@ -0,0 +1,152 @@
+package model
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Envelope event types, one per published stream
+const (
+	EventContent            = "content"
+	EventCreator            = "creator"
+	EventLive               = "live_event"
+	EventComment            = "comment"
+	EventMessage            = "message"
+	EventReport             = "report"
+	EventModerationDecision = "moderation_decision"
+	EventTransaction        = "transaction"
+	EventPayout             = "payout"
+	EventBalanceSnapshot    = "balance_snapshot"
+	EventFraudLabel         = "fraud_label"
+	EventPromotion          = "promotion_event"
+	EventExchangeRates      = "exchange_rates"
+	EventRejected           = "rejected_event"
+)
+
+// EventTypes lists every envelope event type
+var EventTypes = []string{
+	EventContent, EventCreator, EventLive, EventComment, EventMessage, EventReport, EventModerationDecision,
+	EventTransaction, EventPayout, EventBalanceSnapshot, EventFraudLabel, EventPromotion, EventExchangeRates,
+	EventRejected,
+}
+
+// Envelope wraps a published event with the metadata consumers need to
+// detect lost, duplicated and reordered events. Sequence starts at 1 and
+// increases by one per event for each (ProducerID, EventType, Key), so a jump
+// means events were lost and a repeat means they were delivered twice.
+type Envelope struct {
+	EventID    string          `json:"event_id"` // ULID, sortable by emit time
+	EventType  string          `json:"event_type"`
+	Key        string          `json:"key"`
+	Sequence   uint64          `json:"sequence"`
+	ProducerID string          `json:"producer_id"` // Sequences restart with every producer instance
+	EmittedAt  time.Time       `json:"emitted_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// Sequencer wraps events in envelopes, numbering them per event type and key.
+// The SequencedStreamsLimit most recently used streams are remembered; a
+// forgotten stream starts again at 1. It is safe for concurrent use.
+type Sequencer struct {
+	producerID string
+
+	mu      sync.Mutex
+	entropy *ulid.MonotonicEntropy
+	streams map[streamKey]*list.Element // Stream to its entry in recent
+	recent  *list.List                  // *streamSequence, most recently used first
+}
+
+// SequencedStreamsLimit is the number of streams whose sequence a Sequencer
+// remembers. Events keyed by one-off IDs such as transactions never reuse
+// their stream, so only long-idle streams are forgotten.
+const SequencedStreamsLimit = 100000
+
+type streamKey struct {
+	eventType string
+	key       string
+}
+
+// streamSequence is the last sequence used in a stream
+type streamSequence struct {
+	stream   streamKey
+	sequence uint64
+}
+
+// NewSequencer returns a sequencer for a producer instance; an empty
+// producerID generates one that is unique to this process
+func NewSequencer(producerID string) *Sequencer {
+	s := &Sequencer{
+		entropy: ulid.Monotonic(rand.Reader, 0),
+		streams: make(map[streamKey]*list.Element),
+		recent:  list.New(),
+	}
+	if producerID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "producer"
+		}
+		producerID = fmt.Sprintf("%s-%s", hostname, s.newID(time.Now()))
+	}
+	s.producerID = producerID
+	return s
+}
+
+// ProducerID returns the producer instance ID stamped on every envelope
+func (s *Sequencer) ProducerID() string {
+	return s.producerID
+}
+
+// Wrap marshals payload and wraps it in the next envelope for eventType and key
+func (s *Sequencer) Wrap(eventType, key string, payload any) (Envelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Envelope{
+		EventID:    s.newID(now),
+		EventType:  eventType,
+		Key:        key,
+		Sequence:   s.next(streamKey{eventType: eventType, key: key}),
+		ProducerID: s.producerID,
+		EmittedAt:  now,
+		Payload:    data,
+	}, nil
+}
+
+// next returns the stream's next sequence, forgetting the least recently
+// used streams beyond SequencedStreamsLimit; s.mu must be held
+func (s *Sequencer) next(stream streamKey) uint64 {
+	if element, ok := s.streams[stream]; ok {
+		s.recent.MoveToFront(element)
+		state := element.Value.(*streamSequence)
+		state.sequence++
+		return state.sequence
+	}
+
+	s.streams[stream] = s.recent.PushFront(&streamSequence{stream: stream, sequence: 1})
+	if s.recent.Len() > SequencedStreamsLimit {
+		oldest := s.recent.Back()
+		s.recent.Remove(oldest)
+		delete(s.streams, oldest.Value.(*streamSequence).stream)
+	}
+	return 1
+}
+
+// newID returns a ULID for the given time; callers other than the
+// constructor must hold s.mu
+func (s *Sequencer) newID(at time.Time) string {
+	return ulid.MustNew(ulid.Timestamp(at), s.entropy).String()
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// Event time is when an event happened on the platform, as opposed to when
+// it was published. Late and replayed events keep their original event time.
+
+// EventTime returns when the content was posted or last updated
+func (c Content) EventTime() time.Time { return c.UpdatedAt }
+
+// EventTime returns when this profile version was published
+func (c Creator) EventTime() time.Time { return c.UpdatedAt }
+
+// EventTime returns when the live event happened
+func (e LiveEvent) EventTime() time.Time { return e.Timestamp }
+
+// EventTime returns when the comment was posted
+func (c Comment) EventTime() time.Time { return c.CreatedAt }
+
+// EventTime returns when the message was sent
+func (m Message) EventTime() time.Time { return m.CreatedAt }
+
+// EventTime returns when the report was filed
+func (r Report) EventTime() time.Time { return r.CreatedAt }
+
+// EventTime returns when the decision was made
+func (d ModerationDecision) EventTime() time.Time { return d.DecidedAt }
+
+// EventTime returns when the transaction was booked
+func (t Transaction) EventTime() time.Time { return t.CreatedAt }
+
+// EventTime returns when the payout was made
+func (p Payout) EventTime() time.Time { return p.CreatedAt }
+
+// EventTime returns when the balance was snapshotted
+func (b BalanceSnapshot) EventTime() time.Time { return b.AsOf }
+
+// EventTime returns when the fraud scenario was injected
+func (l FraudLabel) EventTime() time.Time { return l.InjectedAt }
+
+// EventTime returns when the promotion started or ended
+func (e PromotionEvent) EventTime() time.Time { return e.Timestamp }
+
+// EventTime returns when the rates were snapshotted
+func (s ExchangeRateSnapshot) EventTime() time.Time { return s.AsOf }
+
+// EventTime returns when the event was rejected
+func (r RejectedEvent) EventTime() time.Time { return r.RejectedAt }
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Fraud scenarios
+const (
+	FraudCardTesting     = "card_testing"
+	FraudLikeFarm        = "like_farm"
+	FraudAccountTakeover = "account_takeover"
+	FraudCollusionRing   = "collusion_ring"
+)
+
+// FraudLabel identifies the entities involved in an injected fraud scenario.
+// Labels are ground truth for training and evaluating fraud detection.
+type FraudLabel struct {
+	ID             string    `json:"id"`
+	Scenario       string    `json:"scenario"`
+	CreatorIDs     []string  `json:"creator_ids,omitempty"`
+	FanIDs         []string  `json:"fan_ids,omitempty"`
+	ContentIDs     []string  `json:"content_ids,omitempty"`
+	TransactionIDs []string  `json:"transaction_ids,omitempty"`
+	Description    string    `json:"description"`
+	InjectedAt     time.Time `json:"injected_at"`
+}
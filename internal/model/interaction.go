@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Interaction author types
+const (
+	AuthorFan     = "fan"
+	AuthorCreator = "creator"
+)
+
+// Comment represents a comment left on published content
+type Comment struct {
+	ID         string    `json:"id"`
+	ThreadID   string    `json:"thread_id"` // One thread per content item
+	ContentID  string    `json:"content_id"`
+	CreatorID  string    `json:"creator_id"`
+	AuthorID   string    `json:"author_id"`
+	AuthorType string    `json:"author_type"`           // "fan" or "creator"
+	ReplyToID  string    `json:"reply_to_id,omitempty"` // Comment being replied to
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Message represents a direct message between a fan and a creator
+type Message struct {
+	ID         string    `json:"id"`
+	ThreadID   string    `json:"thread_id"` // One thread per fan and creator pair
+	CreatorID  string    `json:"creator_id"`
+	FanID      string    `json:"fan_id"`
+	SenderType string    `json:"sender_type"` // "fan" or "creator"
+	Text       string    `json:"text"`
+	MediaURL   string    `json:"media_url,omitempty"`
+	Price      Money     `json:"price_money"` // Zero for free messages
+	IsLocked   bool      `json:"is_locked"`   // Paid media requiring unlock
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,128 @@
+package model
+
+import "encoding/json"
+
+// LegacyFloatMoney makes events also carry their amounts as the float fields
+// (and top-level currency) used before Money was introduced, so existing
+// consumers keep working. Floats can't represent every amount exactly; new
+// consumers should read the *_money fields.
+var LegacyFloatMoney = true
+
+// MarshalJSON adds the legacy price fields when LegacyFloatMoney is set
+func (c Content) MarshalJSON() ([]byte, error) {
+	type content Content
+	if !LegacyFloatMoney {
+		return json.Marshal(content(c))
+	}
+	return json.Marshal(struct {
+		content
+		Price    float64 `json:"price"`
+		Currency string  `json:"currency"`
+	}{content(c), c.Price.Float(), c.Price.Currency})
+}
+
+// MarshalJSON adds the legacy price field when LegacyFloatMoney is set
+func (c Creator) MarshalJSON() ([]byte, error) {
+	type creator Creator
+	if !LegacyFloatMoney {
+		return json.Marshal(creator(c))
+	}
+	return json.Marshal(struct {
+		creator
+		MonthlyPrice float64 `json:"monthly_price"`
+	}{creator(c), c.MonthlyPrice.Float()})
+}
+
+// MarshalJSON adds the legacy price fields when LegacyFloatMoney is set
+func (m Message) MarshalJSON() ([]byte, error) {
+	type message Message
+	if !LegacyFloatMoney {
+		return json.Marshal(message(m))
+	}
+	return json.Marshal(struct {
+		message
+		Price    float64 `json:"price"`
+		Currency string  `json:"currency"`
+	}{message(m), m.Price.Float(), m.Price.Currency})
+}
+
+// MarshalJSON adds the legacy tip fields when LegacyFloatMoney is set
+func (e LiveEvent) MarshalJSON() ([]byte, error) {
+	type liveEvent LiveEvent
+	if !LegacyFloatMoney {
+		return json.Marshal(liveEvent(e))
+	}
+	legacy := struct {
+		liveEvent
+		TipAmount float64 `json:"tip_amount,omitempty"`
+		TotalTips float64 `json:"total_tips,omitempty"`
+		Currency  string  `json:"currency,omitempty"`
+	}{liveEvent: liveEvent(e)}
+	if e.TipAmount != nil {
+		legacy.TipAmount = e.TipAmount.Float()
+		legacy.Currency = e.TipAmount.Currency
+	}
+	if e.TotalTips != nil {
+		legacy.TotalTips = e.TotalTips.Float()
+		legacy.Currency = e.TotalTips.Currency
+	}
+	return json.Marshal(legacy)
+}
+
+// MarshalJSON adds the legacy amount fields when LegacyFloatMoney is set
+func (t Transaction) MarshalJSON() ([]byte, error) {
+	type transaction Transaction
+	if !LegacyFloatMoney {
+		return json.Marshal(transaction(t))
+	}
+	return json.Marshal(struct {
+		transaction
+		Currency    string  `json:"currency"`
+		Gross       float64 `json:"gross"`
+		PlatformFee float64 `json:"platform_fee"`
+		Net         float64 `json:"net"`
+	}{transaction(t), t.Gross.Currency, t.Gross.Float(), t.PlatformFee.Float(), t.Net.Float()})
+}
+
+// MarshalJSON adds the legacy amount fields when LegacyFloatMoney is set
+func (p Payout) MarshalJSON() ([]byte, error) {
+	type payout Payout
+	if !LegacyFloatMoney {
+		return json.Marshal(payout(p))
+	}
+	return json.Marshal(struct {
+		payout
+		Amount   float64 `json:"amount"`
+		Currency string  `json:"currency"`
+	}{payout(p), p.Amount.Float(), p.Amount.Currency})
+}
+
+// MarshalJSON adds the legacy amount fields when LegacyFloatMoney is set
+func (b BalanceSnapshot) MarshalJSON() ([]byte, error) {
+	type balanceSnapshot BalanceSnapshot
+	if !LegacyFloatMoney {
+		return json.Marshal(balanceSnapshot(b))
+	}
+	return json.Marshal(struct {
+		balanceSnapshot
+		Currency     string  `json:"currency"`
+		Gross        float64 `json:"gross"`
+		PlatformFees float64 `json:"platform_fees"`
+		Net          float64 `json:"net"`
+		PaidOut      float64 `json:"paid_out"`
+		Balance      float64 `json:"balance"`
+	}{balanceSnapshot(b), b.Balance.Currency, b.Gross.Float(), b.PlatformFees.Float(), b.Net.Float(), b.PaidOut.Float(), b.Balance.Float()})
+}
+
+// MarshalJSON adds the legacy price fields when LegacyFloatMoney is set
+func (e PromotionEvent) MarshalJSON() ([]byte, error) {
+	type promotionEvent PromotionEvent
+	if !LegacyFloatMoney {
+		return json.Marshal(promotionEvent(e))
+	}
+	return json.Marshal(struct {
+		promotionEvent
+		MonthlyPrice float64 `json:"monthly_price"`
+		Currency     string  `json:"currency"`
+	}{promotionEvent(e), e.MonthlyPrice.Float(), e.MonthlyPrice.Currency})
+}
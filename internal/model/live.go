@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Live session event types
+const (
+	LiveStarted     = "live.started"
+	LiveViewerCount = "live.viewer_count"
+	LiveTip         = "live.tip"
+	LiveEnded       = "live.ended"
+)
+
+// LiveEvent represents telemetry from a creator's live streaming session
+type LiveEvent struct {
+	EventType       string    `json:"event_type"`
+	SessionID       string    `json:"session_id"`
+	CreatorID       string    `json:"creator_id"`
+	Timestamp       time.Time `json:"timestamp"`
+	Title           string    `json:"title,omitempty"`            // live.started
+	ViewerCount     int       `json:"viewer_count"`               // Current viewers
+	PeakViewers     int       `json:"peak_viewers,omitempty"`     // live.viewer_count, live.ended
+	FanID           string    `json:"fan_id,omitempty"`           // live.tip
+	TipAmount       *Money    `json:"tip_amount_money,omitempty"` // live.tip
+	TransactionID   string    `json:"transaction_id,omitempty"`   // live.tip
+	TotalTips       *Money    `json:"total_tips_money,omitempty"` // live.ended
+	DurationSeconds int       `json:"duration_seconds,omitempty"` // live.ended
+}
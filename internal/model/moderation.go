@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// Report and moderation target types
+const (
+	TargetContent = "content"
+	TargetCreator = "creator"
+)
+
+// Moderation decisions
+const (
+	DecisionApproved         = "approved"
+	DecisionRemoved          = "removed"
+	DecisionCreatorSuspended = "creator_suspended"
+)
+
+// Report represents a fan reporting content or a creator
+type Report struct {
+	ID         string    `json:"id"`
+	ReporterID string    `json:"reporter_id"`
+	TargetType string    `json:"target_type"` // "content" or "creator"
+	TargetID   string    `json:"target_id"`
+	CreatorID  string    `json:"creator_id"`
+	Reason     string    `json:"reason"`
+	Details    string    `json:"details,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ModerationDecision represents the outcome of reviewing a report
+type ModerationDecision struct {
+	ID          string    `json:"id"`
+	ReportID    string    `json:"report_id"`
+	TargetType  string    `json:"target_type"`
+	TargetID    string    `json:"target_id"`
+	CreatorID   string    `json:"creator_id"`
+	Decision    string    `json:"decision"` // "approved", "removed" or "creator_suspended"
+	Reason      string    `json:"reason"`
+	ModeratorID string    `json:"moderator_id"`
+	DecidedAt   time.Time `json:"decided_at"`
+}
+
+// RemovedContent identifies content taken down by moderation, to be
+// tombstoned
+type RemovedContent struct {
+	ContentID string
+	CreatorID string
+}
+
+// Report reasons for simulation
+var ReportReasons = []string{
+	"spam",
+	"harassment",
+	"copyright",
+	"underage_concern",
+	"impersonation",
+	"scam",
+	"illegal_content",
+	"other",
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Money is an exact amount in integer minor units (e.g. cents) of a currency
+type Money struct {
+	AmountMinor int64  `json:"amount_minor"`
+	Currency    string `json:"currency"`
+}
+
+// NewMoney returns minor units of currency as Money
+func NewMoney(minor int64, currency string) Money {
+	return Money{AmountMinor: minor, Currency: currency}
+}
+
+// MoneyFromFloat rounds a decimal amount to the nearest minor unit of currency
+func MoneyFromFloat(amount float64, currency string) Money {
+	return Money{AmountMinor: ToMinor(amount, currency), Currency: currency}
+}
+
+// Float returns the amount in major units. Use it for display and legacy
+// fields only; sums should be taken over AmountMinor.
+func (m Money) Float() float64 {
+	return FromMinor(m.AmountMinor, m.Currency)
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.AmountMinor == 0
+}
+
+// Add returns m + other; both must be in the same currency
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	return Money{AmountMinor: m.AmountMinor + other.AmountMinor, Currency: m.Currency}
+}
+
+// Sub returns m - other; both must be in the same currency
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	return Money{AmountMinor: m.AmountMinor - other.AmountMinor, Currency: m.Currency}
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{AmountMinor: -m.AmountMinor, Currency: m.Currency}
+}
+
+// Mul returns m scaled by factor, rounded to the nearest minor unit
+func (m Money) Mul(factor float64) Money {
+	return Money{AmountMinor: int64(math.Round(float64(m.AmountMinor) * factor)), Currency: m.Currency}
+}
+
+// String formats the amount with the currency's decimal places, e.g. "12.99 USD"
+func (m Money) String() string {
+	return strconv.FormatFloat(m.Float(), 'f', CurrencyExponent(m.Currency), 64) + " " + m.Currency
+}
+
+func (m Money) mustMatch(other Money) {
+	if m.Currency != other.Currency && m.Currency != "" && other.Currency != "" {
+		panic(fmt.Sprintf("money currency mismatch: %s and %s", m.Currency, other.Currency))
+	}
+}
@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+// Transaction types
+const (
+	TransactionSubscription = "subscription"
+	TransactionTip          = "tip"
+	TransactionUnlock       = "unlock"
+	TransactionRefund       = "refund"
+	TransactionChargeback   = "chargeback"
+)
+
+// Transaction represents a fan payment to a creator
+type Transaction struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"` // "subscription", "tip", "unlock", "refund" or "chargeback"
+	FanID       string    `json:"fan_id"`
+	CreatorID   string    `json:"creator_id"`
+	ReferenceID string    `json:"reference_id,omitempty"` // Content, message or live session paid for
+	FanCountry  string    `json:"fan_country"`
+	Gross       Money     `json:"gross_money"` // Amount paid by the fan in the creator's currency; negative for reversals
+	PlatformFee Money     `json:"platform_fee_money"`
+	Net         Money     `json:"net_money"` // Gross minus platform fee, credited to the creator
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Set on refunds and chargebacks
+	OriginalTransactionID string `json:"original_transaction_id,omitempty"`
+	Reason                string `json:"reason,omitempty"`
+
+	FanFlagged bool `json:"fan_flagged,omitempty"` // Fan is flagged as a fraud risk
+
+	// Set on subscriptions
+	BundleMonths int    `json:"bundle_months,omitempty"` // Months purchased, when more than one
+	PromotionID  string `json:"promotion_id,omitempty"`  // Promotion applied to the price
+}
+
+// Refund and chargeback reasons for simulation
+var (
+	RefundReasons     = []string{"duplicate_charge", "content_not_delivered", "accidental_purchase", "customer_request"}
+	ChargebackReasons = []string{"fraudulent", "unrecognized", "product_not_received", "subscription_canceled"}
+)
+
+// Payout represents a transfer of a creator's balance to the creator
+type Payout struct {
+	ID               string    `json:"id"`
+	CreatorID        string    `json:"creator_id"`
+	Amount           Money     `json:"amount_money"`
+	TransactionCount int       `json:"transaction_count"` // Transactions settled by this payout
+	PeriodStart      time.Time `json:"period_start"`
+	PeriodEnd        time.Time `json:"period_end"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// BalanceSnapshot is a creator's cumulative earnings ledger at a point in time.
+// Balance always equals Net minus PaidOut.
+type BalanceSnapshot struct {
+	CreatorID        string    `json:"creator_id"`
+	Gross            Money     `json:"gross_money"`
+	PlatformFees     Money     `json:"platform_fees_money"`
+	Net              Money     `json:"net_money"`
+	PaidOut          Money     `json:"paid_out_money"`
+	Balance          Money     `json:"balance_money"`
+	TransactionCount int       `json:"transaction_count"`
+	PayoutCount      int       `json:"payout_count"`
+	AsOf             time.Time `json:"as_of"`
+}
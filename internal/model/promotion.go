@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// Promotion types
+const (
+	PromotionPercentOff = "percent_off"
+	PromotionFreeTrial  = "free_trial"
+)
+
+// Promotion event types
+const (
+	PromotionStarted = "promotion.started"
+	PromotionEnded   = "promotion.ended"
+)
+
+// Promotion is a time-limited discount on a creator's subscription
+type Promotion struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`                      // "percent_off" or "free_trial"
+	PercentOff    int       `json:"percent_off,omitempty"`     // percent_off
+	FreeTrialDays int       `json:"free_trial_days,omitempty"` // free_trial
+	StartsAt      time.Time `json:"starts_at"`
+	EndsAt        time.Time `json:"ends_at"`
+}
+
+// Bundle is a multi-month subscription sold at a discount
+type Bundle struct {
+	Months          int `json:"months"`
+	DiscountPercent int `json:"discount_percent"`
+}
+
+// PromotionEvent marks the start or end of a creator promotion
+type PromotionEvent struct {
+	EventType    string    `json:"event_type"` // "promotion.started" or "promotion.ended"
+	CreatorID    string    `json:"creator_id"`
+	Promotion    Promotion `json:"promotion"`
+	MonthlyPrice Money     `json:"monthly_price_money"` // Regular price at the time of the event
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// IsActive reports whether the promotion applies at the given time
+func (p *Promotion) IsActive(at time.Time) bool {
+	return p != nil && !at.Before(p.StartsAt) && at.Before(p.EndsAt)
+}
+
+// EffectivePrice returns what a new subscriber pays for the given number of
+// months at the given time, after bundle discounts and any active promotion.
+// Free trials waive the first month; the rest of a bundle is still paid.
+func (c Creator) EffectivePrice(months int, at time.Time) Money {
+	if months < 1 {
+		months = 1
+	}
+
+	discount := 1.0
+	for _, bundle := range c.Bundles {
+		if bundle.Months == months {
+			discount *= 1 - float64(bundle.DiscountPercent)/100
+			break
+		}
+	}
+
+	paidMonths := months
+	if c.Promotion.IsActive(at) {
+		switch c.Promotion.Type {
+		case PromotionPercentOff:
+			discount *= 1 - float64(c.Promotion.PercentOff)/100
+		case PromotionFreeTrial:
+			paidMonths--
+		}
+	}
+
+	return c.MonthlyPrice.Mul(float64(paidMonths) * discount)
+}
@@ -0,0 +1,121 @@
+package model
+
+// Sequence check results
+const (
+	SequenceOK        = "ok"
+	SequenceGap       = "gap"       // Events before this one were never seen
+	SequenceLate      = "late"      // Fills an earlier gap
+	SequenceDuplicate = "duplicate" // Already seen
+)
+
+// SequenceResult describes how an envelope fits its stream
+type SequenceResult struct {
+	Status  string
+	Missing uint64 // Events skipped, for gaps
+}
+
+// SequenceChecker tracks envelope sequences per producer, event type and key.
+// It is not safe for concurrent use.
+type SequenceChecker struct {
+	fromStart bool
+	streams   map[checkerKey]*streamState
+}
+
+type checkerKey struct {
+	producerID string
+	eventType  string
+	key        string
+}
+
+type streamState struct {
+	last    uint64
+	missing []seqRange // Sequences skipped by gaps and not yet seen, in order
+}
+
+// seqRange is an inclusive range of sequences; gaps are kept as ranges so a
+// corrupt, huge sequence costs one entry rather than one per skipped event
+type seqRange struct {
+	from, to uint64
+}
+
+// NewSequenceChecker returns an empty checker. With fromStart, for consumers
+// reading from the log start, every stream begins at sequence 1 and events
+// before the first one seen are missing. Otherwise, for consumers starting
+// mid-way, streams begin at their first sequence seen.
+func NewSequenceChecker(fromStart bool) *SequenceChecker {
+	return &SequenceChecker{fromStart: fromStart, streams: make(map[checkerKey]*streamState)}
+}
+
+// Check records an envelope and reports whether it was in order, skipped
+// events, filled an earlier gap or repeated an event already seen.
+func (c *SequenceChecker) Check(env Envelope) SequenceResult {
+	key := checkerKey{producerID: env.ProducerID, eventType: env.EventType, key: env.Key}
+	state, ok := c.streams[key]
+	if !ok {
+		state = &streamState{last: env.Sequence}
+		c.streams[key] = state
+		if !c.fromStart || env.Sequence <= 1 {
+			return SequenceResult{Status: SequenceOK}
+		}
+		state.missing = append(state.missing, seqRange{from: 1, to: env.Sequence - 1})
+		return SequenceResult{Status: SequenceGap, Missing: env.Sequence - 1}
+	}
+
+	switch {
+	case env.Sequence == state.last+1:
+		state.last = env.Sequence
+		return SequenceResult{Status: SequenceOK}
+
+	case env.Sequence > state.last+1:
+		state.missing = append(state.missing, seqRange{from: state.last + 1, to: env.Sequence - 1})
+		missing := env.Sequence - state.last - 1
+		state.last = env.Sequence
+		return SequenceResult{Status: SequenceGap, Missing: missing}
+
+	case state.fill(env.Sequence):
+		return SequenceResult{Status: SequenceLate}
+
+	default:
+		return SequenceResult{Status: SequenceDuplicate}
+	}
+}
+
+// fill removes seq from the missing ranges, reporting whether it was missing
+func (s *streamState) fill(seq uint64) bool {
+	for i, r := range s.missing {
+		if seq < r.from || seq > r.to {
+			continue
+		}
+		switch {
+		case r.from == r.to:
+			s.missing = append(s.missing[:i], s.missing[i+1:]...)
+		case seq == r.from:
+			s.missing[i].from++
+		case seq == r.to:
+			s.missing[i].to--
+		default:
+			s.missing = append(s.missing[:i+1], s.missing[i:]...)
+			s.missing[i].to = seq - 1
+			s.missing[i+1].from = seq + 1
+		}
+		return true
+	}
+	return false
+}
+
+// Outstanding returns the number of sequences skipped by gaps that have not
+// arrived late
+func (c *SequenceChecker) Outstanding() uint64 {
+	var total uint64
+	for _, state := range c.streams {
+		for _, r := range state.missing {
+			total += r.to - r.from + 1
+		}
+	}
+	return total
+}
+
+// Streams returns the number of streams seen
+func (c *SequenceChecker) Streams() int {
+	return len(c.streams)
+}
@@ -0,0 +1,302 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ValidationError lists every rule an event breaks
+type ValidationError struct {
+	EventType string
+	Problems  []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.EventType, strings.Join(e.Problems, "; "))
+}
+
+// RejectedEvent is an event that failed validation, kept for inspection
+type RejectedEvent struct {
+	EventType  string    `json:"event_type"`
+	Key        string    `json:"key"`
+	Problems   []string  `json:"problems"`
+	Event      any       `json:"event"`
+	RejectedAt time.Time `json:"rejected_at"`
+}
+
+// checks collects broken rules for one event
+type checks struct {
+	eventType string
+	problems  []string
+}
+
+func (c *checks) require(ok bool, format string, args ...any) {
+	if !ok {
+		c.problems = append(c.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *checks) nonEmpty(field, value string) {
+	c.require(value != "", "%s is empty", field)
+}
+
+func (c *checks) nonNegative(field string, value int) {
+	c.require(value >= 0, "%s is negative (%d)", field, value)
+}
+
+func (c *checks) oneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	c.problems = append(c.problems, fmt.Sprintf("%s %q is not one of %s", field, value, strings.Join(allowed, ", ")))
+}
+
+func (c *checks) timestamp(field string, value time.Time) {
+	c.require(!value.IsZero(), "%s is not set", field)
+}
+
+func (c *checks) money(field string, value Money) {
+	c.require(isKnownCurrency(value.Currency), "%s has unknown currency %q", field, value.Currency)
+}
+
+func (c *checks) err() error {
+	if len(c.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{EventType: c.eventType, Problems: c.problems}
+}
+
+func isKnownCurrency(currency string) bool {
+	for _, c := range CountryCurrencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that content is internally consistent
+func (c Content) Validate() error {
+	v := checks{eventType: EventContent}
+	v.nonEmpty("id", c.ID)
+	v.nonEmpty("creator_id", c.CreatorID)
+	v.nonEmpty("title", c.Title)
+	v.oneOf("content_type", c.ContentType, ContentTypes...)
+	v.nonNegative("view_count", c.ViewCount)
+	v.nonNegative("like_count", c.LikeCount)
+	v.money("price", c.Price)
+	v.require(c.IsLocked == (c.Price.AmountMinor > 0), "price %s does not match is_locked %t", c.Price, c.IsLocked)
+	v.timestamp("created_at", c.CreatedAt)
+	v.require(!c.UpdatedAt.Before(c.CreatedAt), "updated_at is before created_at")
+	return v.err()
+}
+
+// Anomalies lists signs of engagement fraud, such as a like farm, that leave
+// content valid but are worth flagging
+func (c Content) Anomalies() []string {
+	var anomalies []string
+	if c.LikeCount > c.ViewCount {
+		anomalies = append(anomalies, fmt.Sprintf("like_count %d exceeds view_count %d", c.LikeCount, c.ViewCount))
+	}
+	return anomalies
+}
+
+// Validate checks that a creator profile is internally consistent
+func (c Creator) Validate() error {
+	v := checks{eventType: EventCreator}
+	v.nonEmpty("id", c.ID)
+	v.nonEmpty("username", c.Username)
+	v.nonNegative("subscriber_count", c.SubscriberCount)
+	v.oneOf("category", c.Category, CreatorCategories...)
+	v.money("monthly_price", c.MonthlyPrice)
+	v.require(c.MonthlyPrice.AmountMinor > 0, "monthly_price %s is not positive", c.MonthlyPrice)
+	v.require(c.Currency == c.MonthlyPrice.Currency, "currency %q does not match monthly_price %s", c.Currency, c.MonthlyPrice)
+	v.require(CountryCurrencies[c.Country] == c.Currency, "country %q does not use currency %q", c.Country, c.Currency)
+	v.require(!(c.IsSuspended && c.IsOnline), "suspended creator is online")
+	v.timestamp("created_at", c.CreatedAt)
+	if p := c.Promotion; p != nil {
+		v.nonEmpty("promotion.id", p.ID)
+		v.oneOf("promotion.type", p.Type, PromotionPercentOff, PromotionFreeTrial)
+		v.require(p.EndsAt.After(p.StartsAt), "promotion ends before it starts")
+		if p.Type == PromotionPercentOff {
+			v.require(p.PercentOff > 0 && p.PercentOff < 100, "promotion.percent_off %d is not between 1 and 99", p.PercentOff)
+		}
+	}
+	for _, b := range c.Bundles {
+		v.require(b.Months > 1, "bundle of %d months", b.Months)
+		v.require(b.DiscountPercent >= 0 && b.DiscountPercent < 100, "bundle discount %d%% is not between 0 and 99", b.DiscountPercent)
+	}
+	return v.err()
+}
+
+// Validate checks that a live event carries the fields its type requires
+func (e LiveEvent) Validate() error {
+	v := checks{eventType: EventLive}
+	v.oneOf("event_type", e.EventType, LiveStarted, LiveViewerCount, LiveTip, LiveEnded)
+	v.nonEmpty("session_id", e.SessionID)
+	v.nonEmpty("creator_id", e.CreatorID)
+	v.nonNegative("viewer_count", e.ViewerCount)
+	v.require(e.PeakViewers == 0 || e.PeakViewers >= e.ViewerCount, "peak_viewers %d is below viewer_count %d", e.PeakViewers, e.ViewerCount)
+	v.timestamp("timestamp", e.Timestamp)
+	switch e.EventType {
+	case LiveTip:
+		v.nonEmpty("fan_id", e.FanID)
+		v.nonEmpty("transaction_id", e.TransactionID)
+		v.require(e.TipAmount != nil && e.TipAmount.AmountMinor > 0, "tip_amount is not positive")
+	case LiveEnded:
+		v.require(e.TotalTips != nil && e.TotalTips.AmountMinor >= 0, "total_tips is missing or negative")
+		v.nonNegative("duration_seconds", e.DurationSeconds)
+	}
+	return v.err()
+}
+
+// Validate checks that a comment is attributed and non-empty
+func (c Comment) Validate() error {
+	v := checks{eventType: EventComment}
+	v.nonEmpty("id", c.ID)
+	v.nonEmpty("thread_id", c.ThreadID)
+	v.nonEmpty("content_id", c.ContentID)
+	v.nonEmpty("creator_id", c.CreatorID)
+	v.nonEmpty("author_id", c.AuthorID)
+	v.oneOf("author_type", c.AuthorType, AuthorFan, AuthorCreator)
+	v.nonEmpty("text", c.Text)
+	v.timestamp("created_at", c.CreatedAt)
+	return v.err()
+}
+
+// Validate checks that a message is attributed and priced consistently
+func (m Message) Validate() error {
+	v := checks{eventType: EventMessage}
+	v.nonEmpty("id", m.ID)
+	v.nonEmpty("thread_id", m.ThreadID)
+	v.nonEmpty("creator_id", m.CreatorID)
+	v.nonEmpty("fan_id", m.FanID)
+	v.oneOf("sender_type", m.SenderType, AuthorFan, AuthorCreator)
+	v.nonEmpty("text", m.Text)
+	v.money("price", m.Price)
+	v.require(m.IsLocked == (m.Price.AmountMinor > 0), "price %s does not match is_locked %t", m.Price, m.IsLocked)
+	v.require(!m.IsLocked || m.MediaURL != "", "locked message has no media_url")
+	v.timestamp("created_at", m.CreatedAt)
+	return v.err()
+}
+
+// Validate checks that a report names its target and reason
+func (r Report) Validate() error {
+	v := checks{eventType: EventReport}
+	v.nonEmpty("id", r.ID)
+	v.nonEmpty("reporter_id", r.ReporterID)
+	v.oneOf("target_type", r.TargetType, TargetContent, TargetCreator)
+	v.nonEmpty("target_id", r.TargetID)
+	v.nonEmpty("creator_id", r.CreatorID)
+	v.oneOf("reason", r.Reason, ReportReasons...)
+	v.timestamp("created_at", r.CreatedAt)
+	return v.err()
+}
+
+// Validate checks that a decision references its report and target
+func (d ModerationDecision) Validate() error {
+	v := checks{eventType: EventModerationDecision}
+	v.nonEmpty("id", d.ID)
+	v.nonEmpty("report_id", d.ReportID)
+	v.oneOf("target_type", d.TargetType, TargetContent, TargetCreator)
+	v.nonEmpty("target_id", d.TargetID)
+	v.nonEmpty("creator_id", d.CreatorID)
+	v.oneOf("decision", d.Decision, DecisionApproved, DecisionRemoved, DecisionCreatorSuspended)
+	v.nonEmpty("moderator_id", d.ModeratorID)
+	v.timestamp("decided_at", d.DecidedAt)
+	return v.err()
+}
+
+// Validate checks that a transaction's amounts add up and that reversals
+// reference the payment they reverse
+func (t Transaction) Validate() error {
+	v := checks{eventType: EventTransaction}
+	v.nonEmpty("id", t.ID)
+	v.oneOf("type", t.Type, TransactionSubscription, TransactionTip, TransactionUnlock, TransactionRefund, TransactionChargeback)
+	v.nonEmpty("fan_id", t.FanID)
+	v.nonEmpty("creator_id", t.CreatorID)
+	v.money("gross", t.Gross)
+	v.require(t.Gross.Currency == t.PlatformFee.Currency && t.Gross.Currency == t.Net.Currency, "amounts are in different currencies")
+	v.require(t.Gross.AmountMinor-t.PlatformFee.AmountMinor == t.Net.AmountMinor, "net %s is not gross %s minus platform_fee %s", t.Net, t.Gross, t.PlatformFee)
+
+	reversal := t.Type == TransactionRefund || t.Type == TransactionChargeback
+	if reversal {
+		v.require(t.Gross.AmountMinor < 0, "reversal gross %s is not negative", t.Gross)
+		v.nonEmpty("original_transaction_id", t.OriginalTransactionID)
+	} else {
+		v.require(t.Gross.AmountMinor >= 0, "gross %s is negative", t.Gross)
+		v.require(t.OriginalTransactionID == "", "payment references original transaction %s", t.OriginalTransactionID)
+	}
+	v.nonNegative("bundle_months", t.BundleMonths)
+	v.timestamp("created_at", t.CreatedAt)
+	return v.err()
+}
+
+// Validate checks that a payout is positive and covers a valid period
+func (p Payout) Validate() error {
+	v := checks{eventType: EventPayout}
+	v.nonEmpty("id", p.ID)
+	v.nonEmpty("creator_id", p.CreatorID)
+	v.money("amount", p.Amount)
+	v.require(p.Amount.AmountMinor > 0, "amount %s is not positive", p.Amount)
+	v.nonNegative("transaction_count", p.TransactionCount)
+	v.require(!p.PeriodEnd.Before(p.PeriodStart), "period ends before it starts")
+	v.timestamp("created_at", p.CreatedAt)
+	return v.err()
+}
+
+// Validate checks that a balance snapshot's ledger adds up
+func (b BalanceSnapshot) Validate() error {
+	v := checks{eventType: EventBalanceSnapshot}
+	v.nonEmpty("creator_id", b.CreatorID)
+	v.money("balance", b.Balance)
+	v.require(b.Gross.AmountMinor-b.PlatformFees.AmountMinor == b.Net.AmountMinor, "net %s is not gross %s minus platform_fees %s", b.Net, b.Gross, b.PlatformFees)
+	v.require(b.Net.AmountMinor-b.PaidOut.AmountMinor == b.Balance.AmountMinor, "balance %s is not net %s minus paid_out %s", b.Balance, b.Net, b.PaidOut)
+	v.require(b.PaidOut.AmountMinor >= 0, "paid_out %s is negative", b.PaidOut)
+	v.nonNegative("transaction_count", b.TransactionCount)
+	v.nonNegative("payout_count", b.PayoutCount)
+	v.timestamp("as_of", b.AsOf)
+	return v.err()
+}
+
+// Validate checks that a fraud label names its scenario and at least one entity
+func (l FraudLabel) Validate() error {
+	v := checks{eventType: EventFraudLabel}
+	v.nonEmpty("id", l.ID)
+	v.oneOf("scenario", l.Scenario, FraudCardTesting, FraudLikeFarm, FraudAccountTakeover, FraudCollusionRing)
+	v.require(len(l.CreatorIDs)+len(l.FanIDs)+len(l.ContentIDs)+len(l.TransactionIDs) > 0, "label names no entities")
+	v.timestamp("injected_at", l.InjectedAt)
+	return v.err()
+}
+
+// Validate checks that a promotion event carries a well-formed promotion
+func (e PromotionEvent) Validate() error {
+	v := checks{eventType: EventPromotion}
+	v.oneOf("event_type", e.EventType, PromotionStarted, PromotionEnded)
+	v.nonEmpty("creator_id", e.CreatorID)
+	v.nonEmpty("promotion.id", e.Promotion.ID)
+	v.oneOf("promotion.type", e.Promotion.Type, PromotionPercentOff, PromotionFreeTrial)
+	v.require(e.Promotion.EndsAt.After(e.Promotion.StartsAt), "promotion ends before it starts")
+	v.money("monthly_price", e.MonthlyPrice)
+	v.timestamp("timestamp", e.Timestamp)
+	return v.err()
+}
+
+// Validate checks that a snapshot quotes a positive rate for every currency
+func (s ExchangeRateSnapshot) Validate() error {
+	v := checks{eventType: EventExchangeRates}
+	v.nonEmpty("id", s.ID)
+	v.require(s.Base == BaseCurrency, "base %q is not %s", s.Base, BaseCurrency)
+	checked := map[string]bool{}
+	for _, currency := range CountryCurrencies {
+		if !checked[currency] {
+			checked[currency] = true
+			v.require(s.Rates[currency] > 0, "rate for %s is missing or not positive", currency)
+		}
+	}
+	v.timestamp("as_of", s.AsOf)
+	return v.err()
+}
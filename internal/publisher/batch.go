@@ -0,0 +1,40 @@
+package publisher
+
+import "onlyfans-event-publisher/internal/model"
+
+// Batch groups the events generated in one simulation cycle
+type Batch struct {
+	Content  []model.Content
+	Creators []model.Creator
+	Live     []model.LiveEvent
+	Comments []model.Comment
+	Messages []model.Message
+
+	Reports   []model.Report
+	Decisions []model.ModerationDecision
+
+	Transactions []model.Transaction
+	Payouts      []model.Payout
+	Balances     []model.BalanceSnapshot
+
+	FraudLabels []model.FraudLabel
+	Promotions  []model.PromotionEvent
+
+	ExchangeRates []model.ExchangeRateSnapshot
+
+	// RemovedContent holds moderated content, published as tombstones (nil
+	// values) on the content topic
+	RemovedContent []model.RemovedContent
+
+	// Rejects holds events that failed validation, published to the reject
+	// topic when one is configured
+	Rejects []model.RejectedEvent
+}
+
+// Len returns the total number of events in the batch
+func (b Batch) Len() int {
+	return len(b.Content) + len(b.Creators) + len(b.Live) + len(b.Comments) + len(b.Messages) +
+		len(b.Reports) + len(b.Decisions) + len(b.RemovedContent) +
+		len(b.Transactions) + len(b.Payouts) + len(b.Balances) + len(b.FraudLabels) + len(b.Promotions) + len(b.ExchangeRates) +
+		len(b.Rejects)
+}
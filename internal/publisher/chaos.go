@@ -0,0 +1,259 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"onlyfans-event-publisher/internal/sink"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// ChaosHeader names the record header that carries the corruption kind
+const ChaosHeader = sink.ChaosHeader
+
+// Chaos corruption kinds
+const (
+	ChaosTruncatedJSON      = "truncated_json"
+	ChaosWrongType          = "wrong_type"           // view_count as a string
+	ChaosMissingCreatorID   = "missing_creator_id"   // creator_id removed
+	ChaosUnknownContentType = "unknown_content_type" // content_type outside model.ContentTypes
+	ChaosOversizedTags      = "oversized_tags"       // tags with thousands of entries
+	ChaosInvalidUTF8        = "invalid_utf8"         // invalid bytes inside a string value
+	ChaosDuplicateKeys      = "duplicate_keys"       // a key repeated with a conflicting value
+)
+
+// ChaosKinds lists every supported corruption kind
+var ChaosKinds = []string{
+	ChaosTruncatedJSON,
+	ChaosWrongType,
+	ChaosMissingCreatorID,
+	ChaosUnknownContentType,
+	ChaosOversizedTags,
+	ChaosInvalidUTF8,
+	ChaosDuplicateKeys,
+}
+
+// invalidUTF8Marker is swapped for invalid UTF-8 after marshalling, since
+// encoding/json would otherwise escape the bytes
+const invalidUTF8Marker = "__chaos_invalid_utf8__"
+
+// Chaos corrupts a fraction of published records so consumers' dead-letter
+// handling gets exercised. Kinds that need a field the record lacks (e.g.
+// content_type on a creator) fall back to a kind that applies to any record.
+type Chaos struct {
+	rate  float64
+	kinds []string
+
+	mu     sync.Mutex
+	rng    *rand.Rand
+	counts map[string]int64
+}
+
+// NewChaos returns a Chaos that corrupts rate of all records using the given
+// kinds; no kinds means all of them
+func NewChaos(rate float64, kinds []string) (*Chaos, error) {
+	if rate < 0 || rate > 1 {
+		return nil, fmt.Errorf("chaos rate must be between 0 and 1")
+	}
+	if len(kinds) == 0 {
+		kinds = ChaosKinds
+	}
+	for _, kind := range kinds {
+		if !isChaosKind(kind) {
+			return nil, fmt.Errorf("unknown chaos kind %q (expected one of %s)", kind, strings.Join(ChaosKinds, ", "))
+		}
+	}
+
+	return &Chaos{
+		rate:   rate,
+		kinds:  kinds,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		counts: make(map[string]int64),
+	}, nil
+}
+
+// ParseChaosKinds splits a comma-separated list of corruption kinds
+func ParseChaosKinds(spec string) []string {
+	var kinds []string
+	for _, kind := range strings.Split(spec, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// Counts returns how many records were corrupted, per kind
+func (c *Chaos) Counts() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[string]int64, len(c.counts))
+	for kind, n := range c.counts {
+		counts[kind] = n
+	}
+	return counts
+}
+
+// Corrupt corrupts a random fraction of records in place and tags each with
+// ChaosHeader. Tombstones are left alone.
+func (c *Chaos) Corrupt(records []*kgo.Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, record := range records {
+		if record.Value == nil || c.rng.Float64() >= c.rate {
+			continue
+		}
+
+		kind := c.kinds[c.rng.Intn(len(c.kinds))]
+		value, applied := c.corrupt(record.Value, kind)
+		record.Value = value
+		record.Headers = append(record.Headers, kgo.RecordHeader{Key: ChaosHeader, Value: []byte(applied)})
+		c.counts[applied]++
+	}
+}
+
+// corrupt applies kind to value, returning the corrupted value and the kind
+// actually applied
+func (c *Chaos) corrupt(value []byte, kind string) ([]byte, string) {
+	if kind == ChaosTruncatedJSON {
+		return c.truncate(value), ChaosTruncatedJSON
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(value, &object); err != nil {
+		return c.truncate(value), ChaosTruncatedJSON
+	}
+
+	// Corrupt the event rather than its envelope
+	fields := object
+	if payload, ok := object["payload"].(map[string]any); ok {
+		fields = payload
+	}
+
+	switch kind {
+	case ChaosWrongType:
+		if views, ok := fields["view_count"].(float64); ok {
+			fields["view_count"] = fmt.Sprintf("%d views", int(views))
+			return marshalChaos(object), kind
+		}
+
+	case ChaosMissingCreatorID:
+		if _, ok := fields["creator_id"]; ok {
+			delete(fields, "creator_id")
+			return marshalChaos(object), kind
+		}
+
+	case ChaosUnknownContentType:
+		if _, ok := fields["content_type"]; ok {
+			fields["content_type"] = "hologram"
+			return marshalChaos(object), kind
+		}
+
+	case ChaosOversizedTags:
+		if _, ok := fields["tags"]; ok {
+			tags := make([]string, 10000)
+			for i := range tags {
+				tags[i] = fmt.Sprintf("tag%d", i)
+			}
+			fields["tags"] = tags
+			return marshalChaos(object), kind
+		}
+
+	case ChaosInvalidUTF8:
+		if field := firstStringField(fields); field != "" {
+			fields[field] = fields[field].(string) + invalidUTF8Marker
+			data := marshalChaos(object)
+			return bytes.Replace(data, []byte(invalidUTF8Marker), []byte{0xff, 0xfe, 0xfd}, 1), kind
+		}
+
+	case ChaosDuplicateKeys:
+		return duplicateKey(object), kind
+	}
+
+	// The record lacks the field this kind needs
+	if c.rng.Float64() < 0.5 {
+		return duplicateKey(object), ChaosDuplicateKeys
+	}
+	return c.truncate(value), ChaosTruncatedJSON
+}
+
+// truncate cuts value short somewhere after its first byte
+func (c *Chaos) truncate(value []byte) []byte {
+	if len(value) < 2 {
+		return []byte("{")
+	}
+	return value[:1+c.rng.Intn(len(value)-1)]
+}
+
+// duplicateKey repeats one of the object's keys with a conflicting value, so
+// parsers that keep the first and the last occurrence disagree
+func duplicateKey(object map[string]any) []byte {
+	if len(object) == 0 {
+		return []byte(`{"id":"chaos-duplicate","id":null}`)
+	}
+
+	var key string
+	for _, candidate := range []string{"creator_id", "id", "event_id"} {
+		if _, ok := object[candidate]; ok {
+			key = candidate
+			break
+		}
+	}
+	if key == "" {
+		keys := make([]string, 0, len(object))
+		for k := range object {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		key = keys[0]
+	}
+
+	data := marshalChaos(object)
+	return append([]byte(fmt.Sprintf(`{%q:"chaos-duplicate",`, key)), data[1:]...)
+}
+
+// firstStringField returns the preferred free-text field, or else the first
+// string field in key order
+func firstStringField(fields map[string]any) string {
+	for _, preferred := range []string{"title", "text", "description", "username"} {
+		if _, ok := fields[preferred].(string); ok {
+			return preferred
+		}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key, value := range fields {
+		if _, ok := value.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
+// marshalChaos marshals a decoded object, which cannot fail
+func marshalChaos(object map[string]any) []byte {
+	data, _ := json.Marshal(object)
+	return data
+}
+
+func isChaosKind(kind string) bool {
+	for _, k := range ChaosKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package publisher
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// EventTimeHeader names the record header that carries the event's own
+// timestamp (RFC 3339), which stays put when the record is delayed or resent
+const EventTimeHeader = "event-time"
+
+// DisorderOptions configures how records are disturbed
+type DisorderOptions struct {
+	LateRate      float64       // Fraction of records held back
+	MaxLateness   time.Duration // Held records are released after up to this long
+	ReorderWindow int           // Records are shuffled within windows of this size; 0 or 1 keeps order
+	DuplicateRate float64       // Fraction of records sent a second time
+}
+
+// DisorderStats counts disturbed records
+type DisorderStats struct {
+	Delayed    int64
+	Reordered  int64 // Records sent at a different position than generated
+	Duplicated int64
+	Held       int // Delayed records not yet released
+}
+
+// Disorder makes records arrive late, out of order or twice, like events from
+// real producers do, so stream processors' windowing and watermarks can be
+// tested. Payloads and the event-time header keep the original event time.
+type Disorder struct {
+	opts DisorderOptions
+
+	mu    sync.Mutex
+	rng   *rand.Rand
+	held  []heldRecord
+	stats DisorderStats
+}
+
+type heldRecord struct {
+	record    *kgo.Record
+	releaseAt time.Time
+}
+
+type pendingDuplicate struct {
+	at     int // Position in the batch before any duplicates are inserted
+	record *kgo.Record
+}
+
+// NewDisorder validates opts and returns a Disorder
+func NewDisorder(opts DisorderOptions) (*Disorder, error) {
+	if opts.LateRate < 0 || opts.LateRate > 1 {
+		return nil, fmt.Errorf("late rate must be between 0 and 1")
+	}
+	if opts.DuplicateRate < 0 || opts.DuplicateRate > 1 {
+		return nil, fmt.Errorf("duplicate rate must be between 0 and 1")
+	}
+	if opts.LateRate > 0 && opts.MaxLateness <= 0 {
+		return nil, fmt.Errorf("max lateness must be positive when late rate is set")
+	}
+	if opts.ReorderWindow < 0 {
+		return nil, fmt.Errorf("reorder window cannot be negative")
+	}
+
+	return &Disorder{
+		opts: opts,
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// Apply returns the records to send now: records not held back, exact
+// duplicates of some of them and previously held records that are due, with
+// the result shuffled within the reorder window
+func (d *Disorder) Apply(records []*kgo.Record, now time.Time) []*kgo.Record {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]*kgo.Record, 0, len(records))
+	for _, record := range records {
+		if d.rng.Float64() < d.opts.LateRate {
+			lateness := time.Duration(d.rng.Int63n(int64(d.opts.MaxLateness))) + 1
+			d.held = append(d.held, heldRecord{record: record, releaseAt: now.Add(lateness)})
+			d.stats.Delayed++
+			continue
+		}
+		out = append(out, record)
+	}
+
+	// Duplicates follow their original somewhere later in the batch. Inserting
+	// from the back keeps the positions picked for earlier ones valid.
+	var duplicates []pendingDuplicate
+	for i, record := range out {
+		if record.Value == nil || d.rng.Float64() >= d.opts.DuplicateRate {
+			continue
+		}
+		duplicates = append(duplicates, pendingDuplicate{at: i + 1 + d.rng.Intn(len(out)-i), record: copyRecord(record)})
+	}
+	sort.SliceStable(duplicates, func(i, j int) bool { return duplicates[i].at > duplicates[j].at })
+	for _, dup := range duplicates {
+		out = slices.Insert(out, dup.at, dup.record)
+		d.stats.Duplicated++
+	}
+
+	// Released records go after everything generated since they were held
+	pending := d.held[:0]
+	for _, h := range d.held {
+		if now.Before(h.releaseAt) {
+			pending = append(pending, h)
+			continue
+		}
+		out = append(out, h.record)
+	}
+	d.held = pending
+
+	d.shuffle(out)
+	return out
+}
+
+// Drain returns every held record regardless of its release time
+func (d *Disorder) Drain() []*kgo.Record {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	records := make([]*kgo.Record, len(d.held))
+	for i, h := range d.held {
+		records[i] = h.record
+	}
+	d.held = nil
+	return records
+}
+
+// Stats returns counts of disturbed records so far
+func (d *Disorder) Stats() DisorderStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := d.stats
+	stats.Held = len(d.held)
+	return stats
+}
+
+// shuffle reorders records within consecutive windows; d.mu must be held
+func (d *Disorder) shuffle(records []*kgo.Record) {
+	window := d.opts.ReorderWindow
+	if window < 2 {
+		return
+	}
+
+	for start := 0; start < len(records); start += window {
+		end := start + window
+		if end > len(records) {
+			end = len(records)
+		}
+		chunk := records[start:end]
+		original := append([]*kgo.Record(nil), chunk...)
+		d.rng.Shuffle(len(chunk), func(i, j int) { chunk[i], chunk[j] = chunk[j], chunk[i] })
+		for i := range chunk {
+			if chunk[i] != original[i] {
+				d.stats.Reordered++
+			}
+		}
+	}
+}
+
+// copyRecord returns an identical, unsent copy of record
+func copyRecord(record *kgo.Record) *kgo.Record {
+	return &kgo.Record{
+		Topic:   record.Topic,
+		Key:     record.Key,
+		Value:   record.Value,
+		Headers: append([]kgo.RecordHeader(nil), record.Headers...),
+	}
+}
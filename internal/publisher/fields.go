@@ -0,0 +1,212 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jsonField locates a struct field by its JSON name
+type jsonField struct {
+	index     []int
+	omitEmpty bool
+}
+
+var (
+	jsonFieldCache sync.Map // reflect.Type to map[string]jsonField
+	timeType       = reflect.TypeOf(time.Time{})
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// eventField returns the field at a dotted JSON path of an event, as
+// encoding/json would decode it into an any, without marshaling the event.
+// Fields are looked up by their json tags; legacy fields that only a
+// MarshalJSON method adds are not seen. Empty omitempty fields are missing,
+// as they would be in the JSON.
+func eventField(event any, path []string) (any, bool) {
+	v := reflect.ValueOf(event)
+	for _, name := range path {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil, false
+			}
+			v = v.Elem()
+		}
+
+		switch {
+		case v.Kind() == reflect.Struct && v.Type() != timeType:
+			field, ok := jsonFields(v.Type())[name]
+			if !ok {
+				return nil, false
+			}
+			v = v.FieldByIndex(field.index)
+			if field.omitEmpty && isEmptyValue(v) {
+				return nil, false
+			}
+		case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	}
+	return jsonValue(v), true
+}
+
+// checkFieldPath reports an error if eventField could never find path in an
+// event of type t: a field name that is not a JSON field of its struct, or a
+// path that continues past a value without fields
+func checkFieldPath(t reflect.Type, path []string) error {
+	for i, name := range path {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		switch {
+		case t.Kind() == reflect.Interface:
+			return nil // Only known at run time
+		case t.Kind() == reflect.Struct && t != timeType:
+			field, ok := jsonFields(t)[name]
+			if !ok {
+				return fmt.Errorf("unknown field %q", strings.Join(path[:i+1], "."))
+			}
+			t = t.FieldByIndex(field.index).Type
+		case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+			t = t.Elem()
+		default:
+			return fmt.Errorf("%s has no fields", strings.Join(path[:i], "."))
+		}
+	}
+	return nil
+}
+
+// jsonValue converts a value to what it would decode to from JSON: nil,
+// bool, float64, string, []any or map[string]any
+func jsonValue(v reflect.Value) any {
+	switch {
+	case v.Type() == timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339Nano)
+	case v.Type().Implements(marshalerType):
+		return viaJSON(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonValue(v.Elem())
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return viaJSON(v) // Base64
+		}
+		fallthrough
+	case reflect.Array:
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = jsonValue(v.Index(i))
+		}
+		return list
+	case reflect.Struct:
+		object := make(map[string]any)
+		for name, field := range jsonFields(v.Type()) {
+			value := v.FieldByIndex(field.index)
+			if field.omitEmpty && isEmptyValue(value) {
+				continue
+			}
+			object[name] = jsonValue(value)
+		}
+		return object
+	}
+	return viaJSON(v)
+}
+
+// viaJSON converts a value the slow way, for types with their own encoding
+func viaJSON(v reflect.Value) any {
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return value
+}
+
+// jsonFields returns a struct type's fields by JSON name
+func jsonFields(t reflect.Type) map[string]jsonField {
+	if fields, ok := jsonFieldCache.Load(t); ok {
+		return fields.(map[string]jsonField)
+	}
+	fields := make(map[string]jsonField)
+	addJSONFields(fields, t, nil)
+	jsonFieldCache.Store(t, fields)
+	return fields
+}
+
+// addJSONFields adds t's fields, and those promoted from embedded structs
+// without a JSON name, to fields; outer fields win
+func addJSONFields(fields map[string]jsonField, t reflect.Type, index []int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addJSONFields(fields, f.Type, fieldIndex)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := fields[name]; ok && len(index) > 0 {
+			continue
+		}
+		fields[name] = jsonField{index: fieldIndex, omitEmpty: strings.Contains(opts, "omitempty")}
+	}
+}
+
+// isEmptyValue reports whether omitempty leaves v out, as encoding/json does
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
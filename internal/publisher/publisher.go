@@ -4,95 +4,132 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	"onlyfans-event-publisher/internal/model"
+	"onlyfans-event-publisher/internal/sink"
 
 	"github.com/twmb/franz-go/pkg/kgo"
-	"github.com/twmb/franz-go/pkg/kmsg"
 )
 
-// PlatformPublisher handles publishing content and creator events to Redpanda
+// PlatformPublisher turns platform events into records and writes them to a
+// sink, Redpanda by default
 type PlatformPublisher struct {
-	client       *kgo.Client
+	sink         sink.Sink
 	contentTopic string
 	creatorTopic string
+	liveTopic    string
+	commentTopic string
+	messageTopic string
+	reportTopic  string
+	modTopic     string
+	txTopic      string
+	payoutTopic  string
+	balanceTopic string
+	fraudTopic   string
+	promoTopic   string
+	fxTopic      string
+	rejectTopic  string // Empty to drop rejected events
+
+	sequencer *model.Sequencer // Wraps events in envelopes when set
+	chaos     *Chaos           // Corrupts a fraction of records when set
+	disorder  *Disorder        // Delays, reorders and duplicates records when set
+	router    *Router          // Picks topics by event attributes when set
 }
 
-// NewPlatformPublisher creates a new platform publisher
-func NewPlatformPublisher(ctx context.Context, brokers, contentTopic, creatorTopic string) (*PlatformPublisher, error) {
-	// Create Redpanda client options
-	opts := []kgo.Opt{
-		kgo.SeedBrokers(strings.Split(brokers, ",")...),
-		kgo.AllowAutoTopicCreation(),
-		kgo.ProducerBatchMaxBytes(1024 * 1024), // 1MB
-		kgo.ProducerLinger(5 * time.Millisecond),
-		kgo.RecordRetries(3),
-		kgo.RetryTimeout(10 * time.Second),
-	}
+// CreatorIDHeader names the record header that carries the creator ID of
+// removed content on its tombstones
+const CreatorIDHeader = "creator-id"
+
+// Topics holds the destination topic for each event kind
+type Topics struct {
+	Content      string
+	Creator      string
+	Live         string
+	Comment      string
+	Message      string
+	Report       string
+	Moderation   string
+	Transaction  string
+	Payout       string
+	Balance      string
+	FraudLabel   string
+	Promotion    string
+	ExchangeRate string
+	Reject       string // Optional
+}
 
-	// Create client
-	client, err := kgo.NewClient(opts...)
+// NewPlatformPublisher creates a new platform publisher that produces to Redpanda
+func NewPlatformPublisher(ctx context.Context, brokers string, topics Topics) (*PlatformPublisher, error) {
+	kafka, err := sink.NewKafka(ctx, brokers)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Redpanda client: %w", err)
-	}
-
-	// Test connection
-	if err := checkConnection(ctx, client); err != nil {
-		client.Close()
 		return nil, err
 	}
+	return NewPublisher(kafka, topics), nil
+}
 
+// NewPublisher creates a platform publisher that writes to the given sink,
+// which it closes on Close
+func NewPublisher(out sink.Sink, topics Topics) *PlatformPublisher {
 	return &PlatformPublisher{
-		client:       client,
-		contentTopic: contentTopic,
-		creatorTopic: creatorTopic,
-	}, nil
+		sink:         out,
+		contentTopic: topics.Content,
+		creatorTopic: topics.Creator,
+		liveTopic:    topics.Live,
+		commentTopic: topics.Comment,
+		messageTopic: topics.Message,
+		reportTopic:  topics.Report,
+		modTopic:     topics.Moderation,
+		txTopic:      topics.Transaction,
+		payoutTopic:  topics.Payout,
+		balanceTopic: topics.Balance,
+		fraudTopic:   topics.FraudLabel,
+		promoTopic:   topics.Promotion,
+		fxTopic:      topics.ExchangeRate,
+		rejectTopic:  topics.Reject,
+	}
 }
 
-// checkConnection verifies the connection to Redpanda
-func checkConnection(ctx context.Context, client *kgo.Client) error {
-	// Attempt to list topics to check connection
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	// Request a list of topics to verify connection
-	req := kmsg.MetadataRequest{
-		Topics: []kmsg.MetadataRequestTopic{},
-	}
+// UseEnvelopes wraps every published event in a model.Envelope numbered by
+// sequencer. Tombstones stay bare so compaction still applies.
+func (p *PlatformPublisher) UseEnvelopes(sequencer *model.Sequencer) {
+	p.sequencer = sequencer
+}
 
-	resp, err := client.Request(ctx, &req)
-	if err != nil {
-		return fmt.Errorf("failed to connect to Redpanda: %w", err)
-	}
+// UseRouter picks each event's topic with router's rules instead of always
+// using the configured topic
+func (p *PlatformPublisher) UseRouter(router *Router) {
+	p.router = router
+}
 
-	metaResp := resp.(*kmsg.MetadataResponse)
-	if len(metaResp.Brokers) == 0 {
-		return fmt.Errorf("no brokers found in Redpanda cluster")
-	}
+// UseChaos corrupts a fraction of every published batch as configured in chaos
+func (p *PlatformPublisher) UseChaos(chaos *Chaos) {
+	p.chaos = chaos
+}
 
-	return nil
+// UseDisorder delays, reorders and duplicates published records as
+// configured in disorder. Call Flush before closing to send held records.
+func (p *PlatformPublisher) UseDisorder(disorder *Disorder) {
+	p.disorder = disorder
 }
 
 // PublishContent publishes a content post to the content topic
 func (p *PlatformPublisher) PublishContent(ctx context.Context, content model.Content) error {
 	// Marshal content to JSON
-	data, err := json.Marshal(content)
+	data, err := encode(p.sequencer, model.EventContent, content.ID, content)
 	if err != nil {
 		return fmt.Errorf("failed to marshal content: %w", err)
 	}
 
 	// Create record with content ID as key
 	record := &kgo.Record{
-		Topic: p.contentTopic,
+		Topic: p.router.Topic(model.EventContent, p.contentTopic, content.ID, content),
 		Key:   []byte(content.ID),
 		Value: data,
 	}
 
 	// Produce record
-	result := p.client.ProduceSync(ctx, record)
-	if err := result.FirstErr(); err != nil {
+	if err := p.sink.Write(ctx, []*kgo.Record{record}); err != nil {
 		return fmt.Errorf("failed to produce content record: %w", err)
 	}
 
@@ -102,21 +139,20 @@ func (p *PlatformPublisher) PublishContent(ctx context.Context, content model.Co
 // PublishCreator publishes a creator update to the creator topic
 func (p *PlatformPublisher) PublishCreator(ctx context.Context, creator model.Creator) error {
 	// Marshal creator to JSON
-	data, err := json.Marshal(creator)
+	data, err := encode(p.sequencer, model.EventCreator, creator.ID, creator)
 	if err != nil {
 		return fmt.Errorf("failed to marshal creator: %w", err)
 	}
 
 	// Create record with creator ID as key
 	record := &kgo.Record{
-		Topic: p.creatorTopic,
+		Topic: p.router.Topic(model.EventCreator, p.creatorTopic, creator.ID, creator),
 		Key:   []byte(creator.ID),
 		Value: data,
 	}
 
 	// Produce record
-	result := p.client.ProduceSync(ctx, record)
-	if err := result.FirstErr(); err != nil {
+	if err := p.sink.Write(ctx, []*kgo.Record{record}); err != nil {
 		return fmt.Errorf("failed to produce creator record: %w", err)
 	}
 
@@ -133,25 +169,22 @@ func (p *PlatformPublisher) PublishContentBatch(ctx context.Context, contents []
 	records := make([]*kgo.Record, len(contents))
 	for i, content := range contents {
 		// Marshal content to JSON
-		data, err := json.Marshal(content)
+		data, err := encode(p.sequencer, model.EventContent, content.ID, content)
 		if err != nil {
 			return fmt.Errorf("failed to marshal content: %w", err)
 		}
 
 		// Create record
 		records[i] = &kgo.Record{
-			Topic: p.contentTopic,
+			Topic: p.router.Topic(model.EventContent, p.contentTopic, content.ID, content),
 			Key:   []byte(content.ID),
 			Value: data,
 		}
 	}
 
 	// Produce records
-	results := p.client.ProduceSync(ctx, records...)
-	for _, result := range results {
-		if err := result.Err; err != nil {
-			return fmt.Errorf("failed to produce content batch: %w", err)
-		}
+	if err := p.sink.Write(ctx, records); err != nil {
+		return fmt.Errorf("failed to produce content batch: %w", err)
 	}
 
 	return nil
@@ -167,25 +200,22 @@ func (p *PlatformPublisher) PublishCreatorBatch(ctx context.Context, creators []
 	records := make([]*kgo.Record, len(creators))
 	for i, creator := range creators {
 		// Marshal creator to JSON
-		data, err := json.Marshal(creator)
+		data, err := encode(p.sequencer, model.EventCreator, creator.ID, creator)
 		if err != nil {
 			return fmt.Errorf("failed to marshal creator: %w", err)
 		}
 
 		// Create record
 		records[i] = &kgo.Record{
-			Topic: p.creatorTopic,
+			Topic: p.router.Topic(model.EventCreator, p.creatorTopic, creator.ID, creator),
 			Key:   []byte(creator.ID),
 			Value: data,
 		}
 	}
 
 	// Produce records
-	results := p.client.ProduceSync(ctx, records...)
-	for _, result := range results {
-		if err := result.Err; err != nil {
-			return fmt.Errorf("failed to produce creator batch: %w", err)
-		}
+	if err := p.sink.Write(ctx, records); err != nil {
+		return fmt.Errorf("failed to produce creator batch: %w", err)
 	}
 
 	return nil
@@ -193,49 +223,212 @@ func (p *PlatformPublisher) PublishCreatorBatch(ctx context.Context, creators []
 
 // PublishMixed publishes both content and creator updates in a single batch
 func (p *PlatformPublisher) PublishMixed(ctx context.Context, contents []model.Content, creators []model.Creator) error {
-	var records []*kgo.Record
+	return p.PublishBatch(ctx, Batch{Content: contents, Creators: creators})
+}
+
+// PublishBatch publishes every event in the batch to its topic in a single produce call
+func (p *PlatformPublisher) PublishBatch(ctx context.Context, batch Batch) error {
+	records, err := p.buildRecords(batch)
+	if err != nil {
+		return err
+	}
+
+	if p.chaos != nil {
+		p.chaos.Corrupt(records)
+	}
+
+	// Held records may come due even when nothing new was generated
+	if p.disorder != nil {
+		records = p.disorder.Apply(records, time.Now())
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	// Produce all records
+	if err := p.sink.Write(ctx, records); err != nil {
+		return fmt.Errorf("failed to produce mixed batch: %w", err)
+	}
+
+	return nil
+}
+
+// Flush sends records still held back for lateness
+func (p *PlatformPublisher) Flush(ctx context.Context) error {
+	if p.disorder == nil {
+		return nil
+	}
+
+	records := p.disorder.Drain()
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := p.sink.Write(ctx, records); err != nil {
+		return fmt.Errorf("failed to flush held records: %w", err)
+	}
+	return nil
+}
+
+// buildRecords marshals every event in the batch into a record for its topic
+func (p *PlatformPublisher) buildRecords(batch Batch) ([]*kgo.Record, error) {
+	records := make([]*kgo.Record, 0, batch.Len())
+	var err error
 
 	// Add content records
-	for _, content := range contents {
-		data, err := json.Marshal(content)
+	records, err = appendRecords(records, p, p.contentTopic, model.EventContent, batch.Content,
+		func(c model.Content) string { return c.ID })
+	if err != nil {
+		return nil, err
+	}
+
+	// Add creator records
+	records, err = appendRecords(records, p, p.creatorTopic, model.EventCreator, batch.Creators,
+		func(c model.Creator) string { return c.ID })
+	if err != nil {
+		return nil, err
+	}
+
+	// Add live session records, keyed by session so each stream stays ordered
+	records, err = appendRecords(records, p, p.liveTopic, model.EventLive, batch.Live,
+		func(e model.LiveEvent) string { return e.SessionID })
+	if err != nil {
+		return nil, err
+	}
+
+	// Add comment and message records, keyed by thread so conversations stay ordered
+	records, err = appendRecords(records, p, p.commentTopic, model.EventComment, batch.Comments,
+		func(c model.Comment) string { return c.ThreadID })
+	if err != nil {
+		return nil, err
+	}
+
+	records, err = appendRecords(records, p, p.messageTopic, model.EventMessage, batch.Messages,
+		func(m model.Message) string { return m.ThreadID })
+	if err != nil {
+		return nil, err
+	}
+
+	// Add report and moderation records, keyed by the reported entity
+	records, err = appendRecords(records, p, p.reportTopic, model.EventReport, batch.Reports,
+		func(r model.Report) string { return r.TargetID })
+	if err != nil {
+		return nil, err
+	}
+
+	records, err = appendRecords(records, p, p.modTopic, model.EventModerationDecision, batch.Decisions,
+		func(d model.ModerationDecision) string { return d.TargetID })
+	if err != nil {
+		return nil, err
+	}
+
+	// Add payment records, keyed by creator so each ledger stays ordered
+	records, err = appendRecords(records, p, p.txTopic, model.EventTransaction, batch.Transactions,
+		func(t model.Transaction) string { return t.CreatorID })
+	if err != nil {
+		return nil, err
+	}
+
+	records, err = appendRecords(records, p, p.payoutTopic, model.EventPayout, batch.Payouts,
+		func(po model.Payout) string { return po.CreatorID })
+	if err != nil {
+		return nil, err
+	}
+
+	records, err = appendRecords(records, p, p.balanceTopic, model.EventBalanceSnapshot, batch.Balances,
+		func(b model.BalanceSnapshot) string { return b.CreatorID })
+	if err != nil {
+		return nil, err
+	}
+
+	// Add fraud labels
+	records, err = appendRecords(records, p, p.fraudTopic, model.EventFraudLabel, batch.FraudLabels,
+		func(l model.FraudLabel) string { return l.ID })
+	if err != nil {
+		return nil, err
+	}
+
+	// Add promotion records, keyed by creator
+	records, err = appendRecords(records, p, p.promoTopic, model.EventPromotion, batch.Promotions,
+		func(e model.PromotionEvent) string { return e.CreatorID })
+	if err != nil {
+		return nil, err
+	}
+
+	// Add exchange rate snapshots, keyed by base currency
+	records, err = appendRecords(records, p, p.fxTopic, model.EventExchangeRates, batch.ExchangeRates,
+		func(r model.ExchangeRateSnapshot) string { return r.Base })
+	if err != nil {
+		return nil, err
+	}
+
+	// Add events that failed validation, if they have somewhere to go
+	if p.rejectTopic != "" {
+		records, err = appendRecords(records, p, p.rejectTopic, model.EventRejected, batch.Rejects,
+			func(r model.RejectedEvent) string { return r.Key })
 		if err != nil {
-			return fmt.Errorf("failed to marshal content: %w", err)
+			return nil, err
 		}
+	}
 
-		records = append(records, &kgo.Record{
-			Topic: p.contentTopic,
-			Key:   []byte(content.ID),
-			Value: data,
-		})
+	// Add tombstones for removed content so compacted topics drop it. The
+	// creator ID lets partitioners that place content by creator find the
+	// post's partition without a value.
+	for _, removed := range batch.RemovedContent {
+		var headers []kgo.RecordHeader
+		if removed.CreatorID != "" {
+			headers = []kgo.RecordHeader{{Key: CreatorIDHeader, Value: []byte(removed.CreatorID)}}
+		}
+		for _, topic := range p.router.TombstoneTopics(p.contentTopic, removed.ContentID) {
+			records = append(records, &kgo.Record{
+				Topic:   topic,
+				Key:     []byte(removed.ContentID),
+				Headers: headers,
+			})
+		}
 	}
 
-	// Add creator records
-	for _, creator := range creators {
-		data, err := json.Marshal(creator)
+	return records, nil
+}
+
+// appendRecords marshals events to JSON and appends them as records for
+// topic, or the topic p's router picks
+func appendRecords[T any](records []*kgo.Record, p *PlatformPublisher, topic, eventType string, events []T, key func(T) string) ([]*kgo.Record, error) {
+	for _, event := range events {
+		k := key(event)
+		data, err := encode(p.sequencer, eventType, k, event)
 		if err != nil {
-			return fmt.Errorf("failed to marshal creator: %w", err)
+			return nil, fmt.Errorf("failed to marshal %s: %w", eventType, err)
 		}
 
-		records = append(records, &kgo.Record{
-			Topic: p.creatorTopic,
-			Key:   []byte(creator.ID),
+		record := &kgo.Record{
+			Topic: p.router.Topic(eventType, topic, k, event),
+			Key:   []byte(k),
 			Value: data,
-		})
+		}
+		if timed, ok := any(event).(interface{ EventTime() time.Time }); ok {
+			record.Headers = append(record.Headers, kgo.RecordHeader{
+				Key:   EventTimeHeader,
+				Value: []byte(timed.EventTime().UTC().Format(time.RFC3339Nano)),
+			})
+		}
+		records = append(records, record)
 	}
+	return records, nil
+}
 
-	if len(records) == 0 {
-		return nil
+// encode marshals an event to JSON, wrapped in an envelope if sequencer is set
+func encode(sequencer *model.Sequencer, eventType, key string, event any) ([]byte, error) {
+	if sequencer == nil {
+		return json.Marshal(event)
 	}
 
-	// Produce all records
-	results := p.client.ProduceSync(ctx, records...)
-	for _, result := range results {
-		if err := result.Err; err != nil {
-			return fmt.Errorf("failed to produce mixed batch: %w", err)
-		}
+	envelope, err := sequencer.Wrap(eventType, key, event)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	return json.Marshal(envelope)
 }
 
 // GetTopics returns the configured topics
@@ -243,9 +436,7 @@ func (p *PlatformPublisher) GetTopics() (contentTopic, creatorTopic string) {
 	return p.contentTopic, p.creatorTopic
 }
 
-// Close closes the Redpanda client
-func (p *PlatformPublisher) Close() {
-	if p.client != nil {
-		p.client.Close()
-	}
-}
\ No newline at end of file
+// Close closes the sink
+func (p *PlatformPublisher) Close() error {
+	return p.sink.Close()
+}
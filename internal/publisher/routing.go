@@ -0,0 +1,499 @@
+package publisher
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// TopicRule sends events that match When to Topic
+type TopicRule struct {
+	When  string `json:"when"` // Conditions joined by &&; empty matches every event
+	Topic string `json:"topic"`
+}
+
+// TopicRules are the routing rules for one event type
+type TopicRules struct {
+	Rules   []TopicRule `json:"rules"`
+	Default string      `json:"default,omitempty"` // Topic for events no rule matches; the configured topic if empty
+}
+
+// Router picks each event's topic from its attributes, so consumers split by
+// domain get only the events they need. Rules are kept per event type and
+// tried in order, and the first match wins. Conditions test the event's JSON
+// fields, e.g.
+//
+//	content_type == "video"
+//	is_locked && price_money.amount_minor >= 1000
+//	!is_verified
+//	tags contains "viral"
+//
+// A bare field matches when it is true, a non-zero number or a non-empty
+// string, list or object. Rules can match attributes that change over a
+// post's life, such as is_viral or view_count, so every topic each of the
+// last RoutedContentLimit posts was routed to is remembered by content ID,
+// and their tombstones go to all of them.
+type Router struct {
+	rules map[string]*routeRules // By event type
+
+	mu      sync.Mutex
+	content map[string]*list.Element // Content ID to its entry in recent
+	recent  *list.List               // *routedContent, most recently routed first
+	counts  map[string]int64         // Routed events per topic
+}
+
+// RoutedContentLimit is the number of posts whose topics a Router remembers,
+// well past the simulator's window of posts that can still be reported
+const RoutedContentLimit = 10000
+
+type routedContent struct {
+	id     string
+	topics []string // In the order the content was first routed to them
+}
+
+// eventModels holds the Go type of each event type, whose JSON fields rules
+// can test
+var eventModels = map[string]reflect.Type{
+	model.EventContent:            reflect.TypeOf(model.Content{}),
+	model.EventCreator:            reflect.TypeOf(model.Creator{}),
+	model.EventLive:               reflect.TypeOf(model.LiveEvent{}),
+	model.EventComment:            reflect.TypeOf(model.Comment{}),
+	model.EventMessage:            reflect.TypeOf(model.Message{}),
+	model.EventReport:             reflect.TypeOf(model.Report{}),
+	model.EventModerationDecision: reflect.TypeOf(model.ModerationDecision{}),
+	model.EventTransaction:        reflect.TypeOf(model.Transaction{}),
+	model.EventPayout:             reflect.TypeOf(model.Payout{}),
+	model.EventBalanceSnapshot:    reflect.TypeOf(model.BalanceSnapshot{}),
+	model.EventFraudLabel:         reflect.TypeOf(model.FraudLabel{}),
+	model.EventPromotion:          reflect.TypeOf(model.PromotionEvent{}),
+	model.EventExchangeRates:      reflect.TypeOf(model.ExchangeRateSnapshot{}),
+	model.EventRejected:           reflect.TypeOf(model.RejectedEvent{}),
+}
+
+type routeRules struct {
+	rules        []routeRule
+	defaultTopic string
+}
+
+type routeRule struct {
+	conditions []condition
+	topic      string
+}
+
+// condition tests one field; op is "" for a bare field and "!" for a negated one
+type condition struct {
+	path  []string
+	op    string
+	value any
+}
+
+// LoadRouter reads routing rules from a JSON file mapping event types to
+// their rules, e.g.
+//
+//	{"content": {"rules": [{"when": "content_type == \"video\"", "topic": "content-video"}]}}
+func LoadRouter(path string) (*Router, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing rules: %w", err)
+	}
+
+	var rules map[string]TopicRules
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("failed to parse routing rules in %s: %w", path, err)
+	}
+	return NewRouter(rules)
+}
+
+// NewRouter compiles routing rules keyed by event type
+func NewRouter(rules map[string]TopicRules) (*Router, error) {
+	r := &Router{
+		rules:   make(map[string]*routeRules, len(rules)),
+		content: make(map[string]*list.Element),
+		recent:  list.New(),
+		counts:  make(map[string]int64),
+	}
+
+	for eventType, set := range rules {
+		if !isEventType(eventType) {
+			return nil, fmt.Errorf("unknown event type %q (expected one of %s)", eventType, strings.Join(model.EventTypes, ", "))
+		}
+		if set.Default != "" && !validTopic(set.Default) {
+			return nil, fmt.Errorf("%s: invalid default topic %q", eventType, set.Default)
+		}
+
+		compiled := &routeRules{defaultTopic: set.Default}
+		for i, rule := range set.Rules {
+			if !validTopic(rule.Topic) {
+				return nil, fmt.Errorf("%s rule %d: invalid topic %q", eventType, i+1, rule.Topic)
+			}
+			conditions, err := parseConditions(rule.When)
+			if err != nil {
+				return nil, fmt.Errorf("%s rule %d: %w", eventType, i+1, err)
+			}
+			for _, c := range conditions {
+				if err := checkFieldPath(eventModels[eventType], c.path); err != nil {
+					return nil, fmt.Errorf("%s rule %d: %w", eventType, i+1, err)
+				}
+			}
+			compiled.rules = append(compiled.rules, routeRule{conditions: conditions, topic: rule.Topic})
+		}
+		r.rules[eventType] = compiled
+	}
+	return r, nil
+}
+
+// Topic returns the topic for an event whose configured topic is topic. A nil
+// Router returns topic.
+func (r *Router) Topic(eventType, topic, key string, event any) string {
+	if r == nil {
+		return topic
+	}
+	rules, ok := r.rules[eventType]
+	if !ok {
+		return topic
+	}
+
+	routed := topic
+	if rules.defaultTopic != "" {
+		routed = rules.defaultTopic
+	}
+	for _, rule := range rules.rules {
+		if rule.match(event) {
+			routed = rule.topic
+			break
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counts[routed]++
+	if eventType == model.EventContent {
+		r.remember(key, routed)
+	}
+	return routed
+}
+
+// remember adds a topic content was routed to, forgetting the least recently
+// routed content beyond RoutedContentLimit; r.mu must be held
+func (r *Router) remember(contentID, topic string) {
+	if element, ok := r.content[contentID]; ok {
+		routed := element.Value.(*routedContent)
+		if !slices.Contains(routed.topics, topic) {
+			routed.topics = append(routed.topics, topic)
+		}
+		r.recent.MoveToFront(element)
+		return
+	}
+
+	r.content[contentID] = r.recent.PushFront(&routedContent{id: contentID, topics: []string{topic}})
+	if r.recent.Len() > RoutedContentLimit {
+		oldest := r.recent.Back()
+		r.recent.Remove(oldest)
+		delete(r.content, oldest.Value.(*routedContent).id)
+	}
+}
+
+// TombstoneTopics returns the topics a tombstone for content goes to, and
+// forgets the content: every topic it was routed to, or every topic the
+// content rules can pick once it has been forgotten. A nil Router returns
+// topic.
+func (r *Router) TombstoneTopics(topic, contentID string) []string {
+	if r == nil || r.rules[model.EventContent] == nil {
+		return []string{topic}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if element, ok := r.content[contentID]; ok {
+		r.recent.Remove(element)
+		delete(r.content, contentID)
+		return element.Value.(*routedContent).topics
+	}
+
+	rules := r.rules[model.EventContent]
+	topics := []string{topic}
+	add := func(t string) {
+		for _, seen := range topics {
+			if seen == t {
+				return
+			}
+		}
+		topics = append(topics, t)
+	}
+	if rules.defaultTopic != "" {
+		add(rules.defaultTopic)
+	}
+	for _, rule := range rules.rules {
+		add(rule.topic)
+	}
+	return topics
+}
+
+// Topics returns every topic the rules name, with its event type
+func (r *Router) Topics() map[string]string {
+	if r == nil {
+		return nil
+	}
+
+	topics := make(map[string]string)
+	for eventType, rules := range r.rules {
+		if rules.defaultTopic != "" {
+			topics[rules.defaultTopic] = eventType
+		}
+		for _, rule := range rules.rules {
+			topics[rule.topic] = eventType
+		}
+	}
+	return topics
+}
+
+// Counts returns the number of routed events per topic
+func (r *Router) Counts() map[string]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[string]int64, len(r.counts))
+	for topic, count := range r.counts {
+		counts[topic] = count
+	}
+	return counts
+}
+
+func (rule routeRule) match(event any) bool {
+	for _, c := range rule.conditions {
+		if !c.match(event) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c condition) match(event any) bool {
+	value, found := eventField(event, c.path)
+
+	switch c.op {
+	case "":
+		return found && truthy(value)
+	case "!":
+		return !found || !truthy(value)
+	case "==":
+		return found && reflect.DeepEqual(value, c.value)
+	case "!=":
+		return !found || !reflect.DeepEqual(value, c.value)
+	case "contains":
+		switch value := value.(type) {
+		case []any:
+			for _, element := range value {
+				if reflect.DeepEqual(element, c.value) {
+					return true
+				}
+			}
+		case string:
+			s, ok := c.value.(string)
+			return ok && strings.Contains(value, s)
+		}
+		return false
+	}
+
+	// Ordering works on numbers and on strings, e.g. RFC 3339 times
+	var cmp int
+	switch value := value.(type) {
+	case float64:
+		limit, ok := c.value.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case value < limit:
+			cmp = -1
+		case value > limit:
+			cmp = 1
+		}
+	case string:
+		limit, ok := c.value.(string)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(value, limit)
+	default:
+		return false
+	}
+
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default: // ">="
+		return cmp >= 0
+	}
+}
+
+func truthy(value any) bool {
+	switch value := value.(type) {
+	case bool:
+		return value
+	case float64:
+		return value != 0
+	case string:
+		return value != ""
+	case []any:
+		return len(value) > 0
+	case map[string]any:
+		return len(value) > 0
+	}
+	return false
+}
+
+// parseConditions parses conditions joined by &&. Each is a field, a field
+// negated with !, or a field compared with ==, !=, <, <=, >, >= or contains
+// to a JSON value.
+func parseConditions(s string) ([]condition, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []condition
+	for len(tokens) > 0 {
+		var c condition
+		if tokens[0] == "!" {
+			c.op = "!"
+			tokens = tokens[1:]
+		}
+		if len(tokens) == 0 || !isFieldToken(tokens[0]) {
+			return nil, fmt.Errorf("expected a field name in %q", s)
+		}
+		c.path = strings.Split(tokens[0], ".")
+		tokens = tokens[1:]
+
+		if c.op == "" && len(tokens) > 0 && isComparison(tokens[0]) {
+			c.op = tokens[0]
+			if len(tokens) < 2 {
+				return nil, fmt.Errorf("missing value after %s in %q", c.op, s)
+			}
+			if err := json.Unmarshal([]byte(tokens[1]), &c.value); err != nil {
+				return nil, fmt.Errorf("invalid value %s in %q, strings need double quotes", tokens[1], s)
+			}
+			tokens = tokens[2:]
+		}
+		conditions = append(conditions, c)
+
+		if len(tokens) == 0 {
+			break
+		}
+		if tokens[0] != "&&" || len(tokens) == 1 {
+			return nil, fmt.Errorf("expected && between conditions in %q", s)
+		}
+		tokens = tokens[1:]
+	}
+	return conditions, nil
+}
+
+// tokenize splits a condition into field names, operators and JSON values
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("unterminated string in %q", s)
+			}
+			tokens = append(tokens, s[i:end+1])
+			i = end + 1
+
+		case strings.HasPrefix(s[i:], "&&"), strings.HasPrefix(s[i:], "=="), strings.HasPrefix(s[i:], "!="),
+			strings.HasPrefix(s[i:], "<="), strings.HasPrefix(s[i:], ">="):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+
+		case c == '!' || c == '<' || c == '>':
+			tokens = append(tokens, s[i:i+1])
+			i++
+
+		default:
+			end := i
+			for end < len(s) && !strings.ContainsRune(" \t\"&=!<>", rune(s[end])) {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("unexpected %q in %q", s[i], s)
+			}
+			tokens = append(tokens, s[i:end])
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func isComparison(token string) bool {
+	switch token {
+	case "==", "!=", "<", "<=", ">", ">=", "contains":
+		return true
+	}
+	return false
+}
+
+// isFieldToken reports whether token is a dotted field name
+func isFieldToken(token string) bool {
+	if isComparison(token) || token == "true" || token == "false" || token == "null" {
+		return false
+	}
+	for _, name := range strings.Split(token, ".") {
+		if name == "" || (name[0] >= '0' && name[0] <= '9') {
+			return false
+		}
+		for _, r := range name {
+			if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validTopic reports whether name is a legal Kafka topic name
+func validTopic(name string) bool {
+	if name == "" || name == "." || name == ".." || len(name) > 249 {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '.' || r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isEventType(eventType string) bool {
+	for _, known := range model.EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
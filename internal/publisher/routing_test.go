@@ -0,0 +1,182 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+func TestEventFieldMatchesJSON(t *testing.T) {
+	legacy := model.LegacyFloatMoney
+	model.LegacyFloatMoney = false
+	defer func() { model.LegacyFloatMoney = legacy }()
+
+	now := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+	events := []any{
+		model.Content{
+			ID: "content-1", CreatorID: "creator-1", Title: "Video", ContentType: "video",
+			Price: model.NewMoney(1299, "USD"), IsLocked: true, ViewCount: 10, CreatedAt: now, UpdatedAt: now,
+			Tags: []string{"fitness", model.TagViral}, IsViral: true,
+		},
+		model.Content{ID: "content-2", CreatorID: "creator-1"}, // Empty omitempty fields
+		model.Creator{ID: "creator-1", Username: "fit", Category: "fitness", IsOnline: true, UpdatedAt: now},
+		&model.LiveEvent{EventType: model.LiveTip, SessionID: "live-1", CreatorID: "creator-1", Timestamp: now, ViewerCount: 3},
+		model.ModerationDecision{ID: "decision-1", TargetType: model.TargetContent, TargetID: "content-1", DecidedAt: now},
+	}
+
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			t.Fatalf("marshal %T: %v", event, err)
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatalf("unmarshal %T: %v", event, err)
+		}
+
+		for name, want := range fields {
+			got, ok := eventField(event, []string{name})
+			if !ok || !reflect.DeepEqual(got, want) {
+				t.Errorf("%T.%s = %#v (found %v), want %#v", event, name, got, ok, want)
+			}
+		}
+		for name := range jsonFields(reflect.Indirect(reflect.ValueOf(event)).Type()) {
+			if _, inJSON := fields[name]; !inJSON {
+				if got, ok := eventField(event, []string{name}); ok {
+					t.Errorf("%T.%s = %#v, want it missing like in the JSON", event, name, got)
+				}
+			}
+		}
+	}
+
+	content := events[0]
+	if got, ok := eventField(content, []string{"price_money", "amount_minor"}); !ok || got != float64(1299) {
+		t.Errorf("price_money.amount_minor = %#v, want 1299", got)
+	}
+	if _, ok := eventField(content, []string{"title", "length"}); ok {
+		t.Error("found a field inside a string")
+	}
+}
+
+func newTestRouter(t *testing.T) *Router {
+	t.Helper()
+	router, err := NewRouter(map[string]TopicRules{
+		model.EventContent: {Rules: []TopicRule{
+			{When: `content_type == "video"`, Topic: "content-video"},
+			{When: `is_locked && price_money.amount_minor >= 1000`, Topic: "content-premium"},
+			{When: `tags contains "viral" && !is_locked`, Topic: "content-viral"},
+		}},
+		model.EventCreator: {Rules: []TopicRule{
+			{When: `category == "fitness"`, Topic: "creators-fitness"},
+		}, Default: "creators-other"},
+	})
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	return router
+}
+
+func TestNewRouterChecksFields(t *testing.T) {
+	for _, eventType := range model.EventTypes {
+		if eventModels[eventType] == nil {
+			t.Errorf("no model for event type %s", eventType)
+		}
+	}
+
+	tests := []struct {
+		eventType string
+		when      string
+		valid     bool
+	}{
+		{model.EventContent, `price_money.amount_minor >= 1000`, true},
+		{model.EventContent, `tags contains "viral" && is_viral`, true},
+		{model.EventLive, `!viewer_count`, true},
+		{model.EventContent, `content_typ == "video"`, false},
+		{model.EventContent, `price >= 10`, false}, // Legacy float amount
+		{model.EventContent, `price_money.amount`, false},
+		{model.EventContent, `title.length > 3`, false},
+		{model.EventCreator, `content_type == "video"`, false},
+	}
+	for _, test := range tests {
+		_, err := NewRouter(map[string]TopicRules{test.eventType: {Rules: []TopicRule{{When: test.when, Topic: "routed"}}}})
+		if (err == nil) != test.valid {
+			t.Errorf("%s rule %q: error = %v, want valid %t", test.eventType, test.when, err, test.valid)
+		}
+	}
+}
+
+func TestRouterTopic(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		eventType string
+		event     any
+		want      string
+	}{
+		{model.EventContent, model.Content{ID: "c1", ContentType: "video"}, "content-video"},
+		{model.EventContent, model.Content{ID: "c2", IsLocked: true, Price: model.NewMoney(1500, "USD")}, "content-premium"},
+		{model.EventContent, model.Content{ID: "c3", IsLocked: true, Price: model.NewMoney(500, "USD")}, "content"},
+		{model.EventContent, &model.Content{ID: "c4", Tags: []string{model.TagViral}}, "content-viral"},
+		{model.EventContent, model.Content{ID: "c5", Tags: []string{model.TagViral}, IsLocked: true}, "content"},
+		{model.EventCreator, model.Creator{ID: "creator-1", Category: "fitness"}, "creators-fitness"},
+		{model.EventCreator, model.Creator{ID: "creator-2", Category: "gaming"}, "creators-other"},
+		{model.EventComment, model.Comment{ID: "comment-1"}, "comments"},
+	}
+	for _, test := range tests {
+		configured := map[string]string{model.EventContent: "content", model.EventCreator: "creator", model.EventComment: "comments"}[test.eventType]
+		if got := router.Topic(test.eventType, configured, fmt.Sprint(test.event), test.event); got != test.want {
+			t.Errorf("Topic(%+v) = %s, want %s", test.event, got, test.want)
+		}
+	}
+}
+
+func TestRouterTombstoneTopics(t *testing.T) {
+	router := newTestRouter(t)
+	router.Topic(model.EventContent, "content", "content-1", model.Content{ID: "content-1", ContentType: "video"})
+	router.Topic(model.EventContent, "content", "content-2", model.Content{ID: "content-2"})
+
+	// A post that went viral after it was published is on two topics
+	router.Topic(model.EventContent, "content", "content-3", model.Content{ID: "content-3"})
+	router.Topic(model.EventContent, "content", "content-3", model.Content{ID: "content-3", Tags: []string{model.TagViral}})
+	router.Topic(model.EventContent, "content", "content-3", model.Content{ID: "content-3"})
+
+	if got := router.TombstoneTopics("content", "content-1"); !slices.Equal(got, []string{"content-video"}) {
+		t.Errorf("tombstone topics = %v, want the routed topic", got)
+	}
+	if got := router.TombstoneTopics("content", "content-3"); !slices.Equal(got, []string{"content", "content-viral"}) {
+		t.Errorf("tombstone topics = %v, want both routed topics", got)
+	}
+	if got := router.TombstoneTopics("content", "content-2"); !slices.Equal(got, []string{"content"}) {
+		t.Errorf("tombstone topics = %v, want the configured topic", got)
+	}
+
+	// Forgotten content is tombstoned everywhere it could have been routed
+	all := []string{"content", "content-video", "content-premium", "content-viral"}
+	if got := router.TombstoneTopics("content", "content-1"); !slices.Equal(got, all) {
+		t.Errorf("tombstone topics = %v, want %v", got, all)
+	}
+
+	for i := 0; i < RoutedContentLimit+10; i++ {
+		id := fmt.Sprintf("content-%d", i)
+		router.Topic(model.EventContent, "content", id, model.Content{ID: id, ContentType: "video"})
+	}
+	if len(router.content) != RoutedContentLimit || router.recent.Len() != RoutedContentLimit {
+		t.Errorf("router remembers %d posts, want %d", len(router.content), RoutedContentLimit)
+	}
+	if got := router.TombstoneTopics("content", "content-0"); !slices.Equal(got, all) {
+		t.Errorf("oldest post still remembered: %v", got)
+	}
+	if got := router.TombstoneTopics("content", fmt.Sprintf("content-%d", RoutedContentLimit+9)); !slices.Equal(got, []string{"content-video"}) {
+		t.Errorf("newest post forgotten: %v", got)
+	}
+
+	var nilRouter *Router
+	if got := nilRouter.TombstoneTopics("content", "content-1"); !slices.Equal(got, []string{"content"}) {
+		t.Errorf("nil router tombstone topics = %v", got)
+	}
+}
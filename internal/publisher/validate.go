@@ -0,0 +1,62 @@
+package publisher
+
+import (
+	"errors"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// validatable is implemented by every model event type
+type validatable interface {
+	Validate() error
+}
+
+// Validate returns the batch without events that fail model validation, with
+// those events added to Rejects. Tombstones are passed through unchecked.
+func (b Batch) Validate() Batch {
+	now := time.Now()
+	rejects := &b.Rejects
+
+	b.Content = keepValid(b.Content, model.EventContent, func(c model.Content) string { return c.ID }, rejects, now)
+	b.Creators = keepValid(b.Creators, model.EventCreator, func(c model.Creator) string { return c.ID }, rejects, now)
+	b.Live = keepValid(b.Live, model.EventLive, func(e model.LiveEvent) string { return e.SessionID }, rejects, now)
+	b.Comments = keepValid(b.Comments, model.EventComment, func(c model.Comment) string { return c.ThreadID }, rejects, now)
+	b.Messages = keepValid(b.Messages, model.EventMessage, func(m model.Message) string { return m.ThreadID }, rejects, now)
+	b.Reports = keepValid(b.Reports, model.EventReport, func(r model.Report) string { return r.TargetID }, rejects, now)
+	b.Decisions = keepValid(b.Decisions, model.EventModerationDecision, func(d model.ModerationDecision) string { return d.TargetID }, rejects, now)
+	b.Transactions = keepValid(b.Transactions, model.EventTransaction, func(t model.Transaction) string { return t.CreatorID }, rejects, now)
+	b.Payouts = keepValid(b.Payouts, model.EventPayout, func(p model.Payout) string { return p.CreatorID }, rejects, now)
+	b.Balances = keepValid(b.Balances, model.EventBalanceSnapshot, func(s model.BalanceSnapshot) string { return s.CreatorID }, rejects, now)
+	b.FraudLabels = keepValid(b.FraudLabels, model.EventFraudLabel, func(l model.FraudLabel) string { return l.ID }, rejects, now)
+	b.Promotions = keepValid(b.Promotions, model.EventPromotion, func(e model.PromotionEvent) string { return e.CreatorID }, rejects, now)
+	b.ExchangeRates = keepValid(b.ExchangeRates, model.EventExchangeRates, func(r model.ExchangeRateSnapshot) string { return r.Base }, rejects, now)
+
+	return b
+}
+
+// keepValid returns the events that pass validation and appends the rest to rejects
+func keepValid[T validatable](events []T, eventType string, key func(T) string, rejects *[]model.RejectedEvent, now time.Time) []T {
+	valid := make([]T, 0, len(events))
+	for _, event := range events {
+		err := event.Validate()
+		if err == nil {
+			valid = append(valid, event)
+			continue
+		}
+
+		problems := []string{err.Error()}
+		var validationErr *model.ValidationError
+		if errors.As(err, &validationErr) {
+			problems = validationErr.Problems
+		}
+		*rejects = append(*rejects, model.RejectedEvent{
+			EventType:  eventType,
+			Key:        key(event),
+			Problems:   problems,
+			Event:      event,
+			RejectedAt: now,
+		})
+	}
+	return valid
+}
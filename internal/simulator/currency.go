@@ -0,0 +1,178 @@
+package simulator
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// Starting value of one USD in each supported currency
+var baseExchangeRates = map[string]float64{
+	"USD": 1,
+	"CAD": 1.36,
+	"MXN": 17.1,
+	"BRL": 5.0,
+	"GBP": 0.79,
+	"EUR": 0.92,
+	"AUD": 1.52,
+	"JPY": 150,
+	"INR": 83,
+}
+
+// CountryWeights is a weighted set of countries to draw creators or fans from
+type CountryWeights struct {
+	countries  []string
+	cumulative []float64 // Running total of weights, normalized to end at 1
+}
+
+// DefaultCountryWeights places everyone in the US
+var DefaultCountryWeights = CountryWeights{countries: []string{"US"}, cumulative: []float64{1}}
+
+// ParseCountryWeights parses weights of the form "US:40,GB:12,DE:8".
+// Every country must be a key of model.CountryCurrencies.
+func ParseCountryWeights(spec string) (CountryWeights, error) {
+	weights := map[string]float64{}
+	total := 0.0
+
+	for _, pair := range strings.Split(spec, ",") {
+		country, rawWeight, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return CountryWeights{}, fmt.Errorf("invalid country weight %q, expected COUNTRY:WEIGHT", pair)
+		}
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if _, known := model.CountryCurrencies[country]; !known {
+			return CountryWeights{}, fmt.Errorf("unsupported country %q", country)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(rawWeight), 64)
+		if err != nil || weight < 0 {
+			return CountryWeights{}, fmt.Errorf("invalid weight for %s: %q", country, rawWeight)
+		}
+		weights[country] += weight
+		total += weight
+	}
+
+	if total <= 0 {
+		return CountryWeights{}, fmt.Errorf("country weights must sum to more than 0")
+	}
+
+	var cw CountryWeights
+	for country := range weights {
+		cw.countries = append(cw.countries, country)
+	}
+	sort.Strings(cw.countries)
+
+	running := 0.0
+	for _, country := range cw.countries {
+		running += weights[country] / total
+		cw.cumulative = append(cw.cumulative, running)
+	}
+
+	return cw, nil
+}
+
+// pick maps u in [0, 1) to a country according to the weights
+func (cw CountryWeights) pick(u float64) string {
+	for i, c := range cw.cumulative {
+		if u < c {
+			return cw.countries[i]
+		}
+	}
+	return cw.countries[len(cw.countries)-1]
+}
+
+func (cw CountryWeights) String() string {
+	parts := make([]string, len(cw.countries))
+	previous := 0.0
+	for i, country := range cw.countries {
+		parts[i] = fmt.Sprintf("%s:%.0f%%", country, (cw.cumulative[i]-previous)*100)
+		previous = cw.cumulative[i]
+	}
+	return strings.Join(parts, ",")
+}
+
+// fanCountry returns the stable country of a fan, derived from its ID
+func (s *PlatformSimulator) fanCountry(fanID string) string {
+	h := fnv.New64a()
+	h.Write([]byte(fanID))
+	return s.fanCountries.pick(float64(h.Sum64()%1000000) / 1000000)
+}
+
+// creatorCurrency returns the currency a creator prices in
+func (s *PlatformSimulator) creatorCurrency(creatorIndex int) string {
+	return s.creators[creatorIndex].Currency
+}
+
+// localPrice converts a USD price point to a creator's currency, keeping
+// local price endings: x.99 for most currencies, round hundreds for yen
+func (s *PlatformSimulator) localPrice(creatorIndex int, usd float64) model.Money {
+	currency := s.creatorCurrency(creatorIndex)
+	return model.MoneyFromFloat(charmPrice(usd*s.exchangeRates[currency], currency), currency)
+}
+
+// localAmount converts a USD amount to a creator's currency. Amounts of 10 or
+// more are rounded to whole units, like the round tips fans actually send.
+func (s *PlatformSimulator) localAmount(creatorIndex int, usd float64) model.Money {
+	currency := s.creatorCurrency(creatorIndex)
+	amount := usd * s.exchangeRates[currency]
+	if amount >= 10 {
+		amount = math.Round(amount)
+	}
+	return model.MoneyFromFloat(amount, currency)
+}
+
+// setMonthlyPrice updates a creator's price, rounded to the currency's minor units
+func setMonthlyPrice(creator *model.Creator, price float64) {
+	creator.MonthlyPrice = model.MoneyFromFloat(price, creator.Currency)
+}
+
+// charmPrice rounds a local amount to a typical price ending for the currency
+func charmPrice(amount float64, currency string) float64 {
+	if model.CurrencyExponent(currency) == 0 {
+		return math.Max(100, math.Round(amount/100)*100)
+	}
+	return math.Floor(amount) + 0.99
+}
+
+// GenerateExchangeRates drifts exchange rates and, once per interval, returns a snapshot
+func (s *PlatformSimulator) GenerateExchangeRates() []model.ExchangeRateSnapshot {
+	now := time.Now()
+	if !s.lastExchangeRates.IsZero() && now.Sub(s.lastExchangeRates) < s.exchangeRateInterval {
+		return nil
+	}
+
+	// Rates move by up to ±0.5% per snapshot
+	for currency, rate := range s.exchangeRates {
+		if currency != model.BaseCurrency {
+			s.exchangeRates[currency] = rate * (1 + (s.rng.Float64()-0.5)*0.01)
+		}
+	}
+
+	rates := make(map[string]float64, len(s.exchangeRates))
+	for currency, rate := range s.exchangeRates {
+		rates[currency] = math.Round(rate*1e6) / 1e6
+	}
+
+	s.lastExchangeRates = now
+	s.exchangeRateCount++
+	return []model.ExchangeRateSnapshot{{
+		ID:    fmt.Sprintf("fx-%d", s.exchangeRateCount),
+		Base:  model.BaseCurrency,
+		Rates: rates,
+		AsOf:  now,
+	}}
+}
+
+// newExchangeRates copies the base exchange rates
+func newExchangeRates() map[string]float64 {
+	rates := make(map[string]float64, len(baseExchangeRates))
+	for currency, rate := range baseExchangeRates {
+		rates[currency] = rate
+	}
+	return rates
+}
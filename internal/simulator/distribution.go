@@ -0,0 +1,236 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Distribution produces random samples used to initialize creators
+type Distribution interface {
+	Sample(rng *rand.Rand) float64
+	String() string
+
+	// Skewed reports whether a few samples dominate, as with power laws
+	Skewed() bool
+}
+
+// Supported distribution kinds
+const (
+	DistUniform   = "uniform"
+	DistLogNormal = "lognormal"
+	DistPareto    = "pareto"
+	DistZipf      = "zipf"
+)
+
+// UniformDistribution samples uniformly from [Min, Max)
+type UniformDistribution struct {
+	Min float64
+	Max float64
+}
+
+// Sample draws a value from the distribution
+func (d UniformDistribution) Sample(rng *rand.Rand) float64 {
+	return d.Min + rng.Float64()*(d.Max-d.Min)
+}
+
+func (d UniformDistribution) String() string {
+	return fmt.Sprintf("uniform(min=%g, max=%g)", d.Min, d.Max)
+}
+
+// Skewed reports false; every value in range is equally likely
+func (d UniformDistribution) Skewed() bool { return false }
+
+// LogNormalDistribution samples exp(Mu + Sigma*N(0,1)), clamped to [Min, Max]
+type LogNormalDistribution struct {
+	Mu    float64
+	Sigma float64
+	Min   float64
+	Max   float64 // 0 means unbounded
+}
+
+// Sample draws a value from the distribution
+func (d LogNormalDistribution) Sample(rng *rand.Rand) float64 {
+	return bound(math.Exp(d.Mu+d.Sigma*rng.NormFloat64()), d.Min, d.Max)
+}
+
+func (d LogNormalDistribution) String() string {
+	return fmt.Sprintf("lognormal(mu=%g, sigma=%g, min=%g, max=%g)", d.Mu, d.Sigma, d.Min, d.Max)
+}
+
+// Skewed reports true; the distribution has a long right tail
+func (d LogNormalDistribution) Skewed() bool { return true }
+
+// ParetoDistribution samples Xm / U^(1/Alpha), optionally capped at Max
+type ParetoDistribution struct {
+	Xm    float64 // Scale (minimum value)
+	Alpha float64 // Shape; smaller values give heavier tails
+	Max   float64 // 0 means unbounded
+}
+
+// Sample draws a value from the distribution
+func (d ParetoDistribution) Sample(rng *rand.Rand) float64 {
+	u := 1 - rng.Float64() // (0, 1]
+	return bound(d.Xm/math.Pow(u, 1/d.Alpha), d.Xm, d.Max)
+}
+
+func (d ParetoDistribution) String() string {
+	return fmt.Sprintf("pareto(xm=%g, alpha=%g, max=%g)", d.Xm, d.Alpha, d.Max)
+}
+
+// Skewed reports true; the distribution is a power law
+func (d ParetoDistribution) Skewed() bool { return true }
+
+// ZipfDistribution samples Min + k where P(k) is proportional to (V+k)^-S, k in [0, Max-Min].
+// It caches a generator for the last rng it sampled with, so it is not safe for
+// concurrent use.
+type ZipfDistribution struct {
+	S   float64 // Exponent, must be > 1
+	V   float64 // Offset, must be >= 1
+	Min float64
+	Max float64
+
+	rng  *rand.Rand
+	zipf *rand.Zipf
+}
+
+// Sample draws a value from the distribution
+func (d *ZipfDistribution) Sample(rng *rand.Rand) float64 {
+	if d.zipf == nil || d.rng != rng {
+		// rand.Zipf keeps its own source; seed it from ours so runs stay reproducible per rng
+		d.rng = rng
+		d.zipf = rand.NewZipf(rand.New(rand.NewSource(rng.Int63())), d.S, d.V, uint64(d.Max-d.Min))
+	}
+	return d.Min + float64(d.zipf.Uint64())
+}
+
+func (d *ZipfDistribution) String() string {
+	return fmt.Sprintf("zipf(s=%g, v=%g, min=%g, max=%g)", d.S, d.V, d.Min, d.Max)
+}
+
+// Skewed reports true; the distribution is a power law
+func (d *ZipfDistribution) Skewed() bool { return true }
+
+// ParseDistribution parses a distribution spec of the form "kind:key=value,key=value".
+// Examples: "uniform:min=100,max=10100", "pareto:xm=100,alpha=1.2,max=500000",
+// "lognormal:mu=7,sigma=1.5", "zipf:s=1.1,v=1,max=500000".
+func ParseDistribution(spec string) (Distribution, error) {
+	kind, rawParams, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	kind = strings.ToLower(strings.TrimSpace(kind))
+
+	params := map[string]float64{}
+	if rawParams != "" {
+		for _, pair := range strings.Split(rawParams, ",") {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid distribution parameter %q in %q", pair, spec)
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %q in %q: %w", key, spec, err)
+			}
+			params[strings.ToLower(strings.TrimSpace(key))] = f
+		}
+	}
+
+	var (
+		dist    Distribution
+		allowed []string
+	)
+
+	switch kind {
+	case DistUniform:
+		allowed = []string{"min", "max"}
+		d := UniformDistribution{Min: param(params, "min", 0), Max: param(params, "max", 1)}
+		if d.Max < d.Min {
+			return nil, fmt.Errorf("uniform distribution requires max >= min")
+		}
+		dist = d
+
+	case DistLogNormal:
+		allowed = []string{"mu", "sigma", "min", "max"}
+		d := LogNormalDistribution{
+			Mu:    param(params, "mu", 0),
+			Sigma: param(params, "sigma", 1),
+			Min:   param(params, "min", 0),
+			Max:   param(params, "max", 0),
+		}
+		if d.Sigma < 0 {
+			return nil, fmt.Errorf("lognormal distribution requires sigma >= 0")
+		}
+		dist = d
+
+	case DistPareto:
+		allowed = []string{"xm", "alpha", "max"}
+		d := ParetoDistribution{
+			Xm:    param(params, "xm", 1),
+			Alpha: param(params, "alpha", 1.16), // The 80/20 rule
+			Max:   param(params, "max", 0),
+		}
+		if d.Xm <= 0 || d.Alpha <= 0 {
+			return nil, fmt.Errorf("pareto distribution requires xm > 0 and alpha > 0")
+		}
+		dist = d
+
+	case DistZipf:
+		allowed = []string{"s", "v", "min", "max"}
+		d := &ZipfDistribution{
+			S:   param(params, "s", 1.1),
+			V:   param(params, "v", 1),
+			Min: param(params, "min", 0),
+			Max: param(params, "max", 1000000),
+		}
+		if d.S <= 1 || d.V < 1 {
+			return nil, fmt.Errorf("zipf distribution requires s > 1 and v >= 1")
+		}
+		if d.Max <= d.Min {
+			return nil, fmt.Errorf("zipf distribution requires max > min")
+		}
+		dist = d
+
+	default:
+		return nil, fmt.Errorf("unknown distribution %q (expected %s, %s, %s or %s)",
+			kind, DistUniform, DistLogNormal, DistPareto, DistZipf)
+	}
+
+	for key := range params {
+		if !contains(allowed, key) {
+			sort.Strings(allowed)
+			return nil, fmt.Errorf("unknown parameter %q for %s distribution (expected one of %s)",
+				key, kind, strings.Join(allowed, ", "))
+		}
+	}
+
+	return dist, nil
+}
+
+// param returns a parsed parameter or its default
+func param(params map[string]float64, key string, fallback float64) float64 {
+	if value, ok := params[key]; ok {
+		return value
+	}
+	return fallback
+}
+
+// bound clamps value to [min, max], treating max <= 0 as unbounded
+func bound(value, min, max float64) float64 {
+	if value < min {
+		return min
+	}
+	if max > 0 && value > max {
+		return max
+	}
+	return value
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
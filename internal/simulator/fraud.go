@@ -0,0 +1,166 @@
+package simulator
+
+import (
+	"fmt"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// FraudRates holds the per-cycle probability of injecting each fraud scenario
+type FraudRates struct {
+	CardTesting     float64 // A stolen card is tested with many tiny tips
+	LikeFarm        float64 // Bots inflate a post's LikeCount
+	AccountTakeover float64 // A hijacked creator account changes email and price
+	CollusionRing   float64 // Fans of a creator ring tip each other's creators
+}
+
+// FraudEvents holds the fraud activity injected in one cycle
+type FraudEvents struct {
+	Labels         []model.FraudLabel
+	ContentUpdates []model.Content // Posts with bot-inflated likes
+}
+
+// GenerateFraud injects the configured fraud scenarios. Payments are booked to
+// the ledger and published by GeneratePayments; hijacked creators are published
+// with their next creator update.
+func (s *PlatformSimulator) GenerateFraud() FraudEvents {
+	var events FraudEvents
+	now := time.Now()
+
+	if s.rng.Float64() < s.fraudRates.CardTesting {
+		events.Labels = append(events.Labels, s.injectCardTesting(now))
+	}
+
+	if len(s.recentContent) > 0 && s.rng.Float64() < s.fraudRates.LikeFarm {
+		label, content := s.injectLikeFarm(now)
+		events.Labels = append(events.Labels, label)
+		events.ContentUpdates = append(events.ContentUpdates, content)
+	}
+
+	if s.rng.Float64() < s.fraudRates.AccountTakeover {
+		events.Labels = append(events.Labels, s.injectAccountTakeover(now))
+	}
+
+	if len(s.creators) >= 3 && s.rng.Float64() < s.fraudRates.CollusionRing {
+		events.Labels = append(events.Labels, s.injectCollusionRing(now))
+	}
+
+	return events
+}
+
+// injectCardTesting sends a burst of tiny tips from a flagged fan to one creator
+func (s *PlatformSimulator) injectCardTesting(now time.Time) model.FraudLabel {
+	creatorIndex := s.rng.Intn(len(s.creators))
+	fanID := s.randomFraudFanID()
+
+	var txIDs []string
+	numTips := 10 + s.rng.Intn(21)
+	for i := 0; i < numTips; i++ {
+		amount := s.localAmount(creatorIndex, float64(50+s.rng.Intn(50))/100) // $0.50-$0.99 equivalent
+		tx := s.recordTransaction(creatorIndex, model.TransactionTip, fanID, "", amount)
+		txIDs = append(txIDs, tx.ID)
+	}
+
+	return s.newFraudLabel(model.FraudCardTesting, now, model.FraudLabel{
+		CreatorIDs:     []string{s.creators[creatorIndex].ID},
+		FanIDs:         []string{fanID},
+		TransactionIDs: txIDs,
+		Description:    fmt.Sprintf("%d tips under $1 from one card in a single cycle", numTips),
+	})
+}
+
+// injectLikeFarm inflates a recent post's likes far beyond its views' like rate
+func (s *PlatformSimulator) injectLikeFarm(now time.Time) (model.FraudLabel, model.Content) {
+	post := s.recentContent[s.rng.Intn(len(s.recentContent))]
+
+	numBots := 5 + s.rng.Intn(16)
+	botIDs := make([]string, numBots)
+	for i := range botIDs {
+		botIDs[i] = fmt.Sprintf("bot-%d", s.rng.Intn(fanPoolSize))
+	}
+
+	// Each bot account likes the post many times over through rotating sessions
+	fakeLikes := numBots * (50 + s.rng.Intn(200))
+	post.content.LikeCount += fakeLikes
+	post.content.UpdatedAt = now
+
+	label := s.newFraudLabel(model.FraudLikeFarm, now, model.FraudLabel{
+		CreatorIDs:  []string{post.content.CreatorID},
+		FanIDs:      botIDs,
+		ContentIDs:  []string{post.content.ID},
+		Description: fmt.Sprintf("%d fake likes from %d bot accounts", fakeLikes, numBots),
+	})
+	return label, post.content
+}
+
+// injectAccountTakeover hijacks a creator account, changing its email and price
+func (s *PlatformSimulator) injectAccountTakeover(now time.Time) model.FraudLabel {
+	creatorIndex := s.rng.Intn(len(s.creators))
+	creator := &s.creators[creatorIndex]
+
+	oldPrice := creator.MonthlyPrice
+	creator.Email = fmt.Sprintf("recovery%d@mail-temp.net", s.rng.Intn(100000))
+	if s.rng.Float64() < 0.5 {
+		creator.MonthlyPrice = s.localPrice(creatorIndex, 99) // Squeeze existing subscribers
+	} else {
+		creator.MonthlyPrice = s.localPrice(creatorIndex, 0) // Dump the price to farm new subscriptions
+	}
+	s.pendingUpdates[creatorIndex] = true
+
+	return s.newFraudLabel(model.FraudAccountTakeover, now, model.FraudLabel{
+		CreatorIDs: []string{creator.ID},
+		Description: fmt.Sprintf("email changed and monthly price moved from %s to %s",
+			oldPrice, creator.MonthlyPrice),
+	})
+}
+
+// injectCollusionRing has each ring member's fan tip every other creator in the ring
+func (s *PlatformSimulator) injectCollusionRing(now time.Time) model.FraudLabel {
+	ringSize := 3 + s.rng.Intn(3)
+	if ringSize > len(s.creators) {
+		ringSize = len(s.creators)
+	}
+	members := s.rng.Perm(len(s.creators))[:ringSize]
+
+	var creatorIDs, fanIDs, txIDs []string
+	for _, member := range members {
+		creatorIDs = append(creatorIDs, s.creators[member].ID)
+		fanIDs = append(fanIDs, s.randomFanID()) // Sock puppet controlled by this ring member
+	}
+
+	for i, fanID := range fanIDs {
+		for j, member := range members {
+			if i == j {
+				continue
+			}
+			amount := s.localAmount(member, float64(20+s.rng.Intn(81))) // $20-$100 equivalent
+			tx := s.recordTransaction(member, model.TransactionTip, fanID, "", amount)
+			txIDs = append(txIDs, tx.ID)
+		}
+	}
+
+	return s.newFraudLabel(model.FraudCollusionRing, now, model.FraudLabel{
+		CreatorIDs:     creatorIDs,
+		FanIDs:         fanIDs,
+		TransactionIDs: txIDs,
+		Description:    fmt.Sprintf("ring of %d creators tipping each other through sock puppet fans", ringSize),
+	})
+}
+
+// newFraudLabel fills in the label ID, scenario and injection time
+func (s *PlatformSimulator) newFraudLabel(scenario string, now time.Time, label model.FraudLabel) model.FraudLabel {
+	s.fraudLabelCount++
+	label.ID = fmt.Sprintf("fraud-%d", s.fraudLabelCount)
+	label.Scenario = scenario
+	label.InjectedAt = now
+	return label
+}
+
+// randomFraudFanID picks a flagged fan, or any fan if none are flagged
+func (s *PlatformSimulator) randomFraudFanID() string {
+	if len(s.fraudFanIDs) == 0 {
+		return s.randomFanID()
+	}
+	return s.fraudFanIDs[s.rng.Intn(len(s.fraudFanIDs))]
+}
@@ -0,0 +1,198 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// maxRecentContent bounds how many recent posts can receive comments
+const maxRecentContent = 200
+
+// recentPost is published content that fans can still comment on
+type recentPost struct {
+	content      model.Content
+	creatorIndex int
+	lastComment  string // Most recent fan comment, for creator replies
+}
+
+// dmThread is an open direct message conversation
+type dmThread struct {
+	id            string
+	creatorIndex  int
+	fanID         string
+	awaitingReply bool // The fan wrote last
+}
+
+// trackRecentContent makes newly published content available for comments
+func (s *PlatformSimulator) trackRecentContent(creatorIndex int, content model.Content) {
+	s.recentContent = append(s.recentContent, &recentPost{content: content, creatorIndex: creatorIndex})
+	if len(s.recentContent) > maxRecentContent {
+		s.recentContent = s.recentContent[len(s.recentContent)-maxRecentContent:]
+	}
+}
+
+// GenerateComments generates fan comments on recent content and creator replies
+func (s *PlatformSimulator) GenerateComments() []model.Comment {
+	var comments []model.Comment
+	now := time.Now()
+
+	for _, post := range s.recentContent {
+		if s.rng.Float64() >= s.commentProbability {
+			continue
+		}
+
+		creator := s.creators[post.creatorIndex]
+		comment := model.Comment{
+			ThreadID:  "thread-" + post.content.ID,
+			ContentID: post.content.ID,
+			CreatorID: creator.ID,
+			CreatedAt: now,
+		}
+
+		// Creators reply to the latest fan comment 20% of the time
+		if post.lastComment != "" && s.rng.Float64() < 0.2 {
+			comment.AuthorID = creator.ID
+			comment.AuthorType = model.AuthorCreator
+			comment.ReplyToID = post.lastComment
+			comment.Text = pickTemplate(creatorReplyTemplates, creator.Category, s.rng)
+		} else {
+			comment.AuthorID = s.randomFanID()
+			comment.AuthorType = model.AuthorFan
+			comment.Text = pickTemplate(fanCommentTemplates, creator.Category, s.rng)
+		}
+
+		s.commentCount++
+		comment.ID = fmt.Sprintf("comment-%d", s.commentCount)
+		if comment.AuthorType == model.AuthorFan {
+			post.lastComment = comment.ID
+		}
+
+		comments = append(comments, comment)
+	}
+
+	return comments
+}
+
+// GenerateMessages generates direct messages between fans and creators.
+// Creators answer threads awaiting a reply and sometimes attach paid media.
+func (s *PlatformSimulator) GenerateMessages() []model.Message {
+	var messages []model.Message
+	now := time.Now()
+
+	for i, creator := range s.creators {
+		if s.rng.Float64() >= s.messageProbability {
+			continue
+		}
+
+		// Fans start or continue a conversation
+		fanID := s.randomFanID()
+		threadID := fmt.Sprintf("dm-%s-%s", creator.ID, fanID)
+		thread, open := s.dmThreads[threadID]
+		if !open {
+			thread = &dmThread{id: threadID, creatorIndex: i, fanID: fanID}
+			s.dmThreads[threadID] = thread
+			s.dmOrder = append(s.dmOrder, threadID)
+		}
+		thread.awaitingReply = true
+
+		messages = append(messages, s.newMessage(threadID, i, fanID, model.AuthorFan, now))
+	}
+
+	// Online creators reply to some of the threads where a fan is waiting
+	for _, threadID := range s.dmOrder {
+		thread := s.dmThreads[threadID]
+		creator := s.creators[thread.creatorIndex]
+		if !thread.awaitingReply || !creator.IsOnline || s.rng.Float64() >= s.messageProbability {
+			continue
+		}
+		thread.awaitingReply = false
+		messages = append(messages, s.newMessage(thread.id, thread.creatorIndex, thread.fanID, model.AuthorCreator, now))
+	}
+
+	// Forget the oldest threads so memory stays bounded
+	const maxThreads = 1000
+	if len(s.dmOrder) > maxThreads {
+		for _, threadID := range s.dmOrder[:len(s.dmOrder)-maxThreads] {
+			delete(s.dmThreads, threadID)
+		}
+		s.dmOrder = s.dmOrder[len(s.dmOrder)-maxThreads:]
+	}
+
+	return messages
+}
+
+// newMessage builds a message in a thread; creator messages may carry paid media
+func (s *PlatformSimulator) newMessage(threadID string, creatorIndex int, fanID, sender string, now time.Time) model.Message {
+	creator := s.creators[creatorIndex]
+	s.messageCount++
+	message := model.Message{
+		ID:         fmt.Sprintf("message-%d", s.messageCount),
+		ThreadID:   threadID,
+		CreatorID:  creator.ID,
+		FanID:      fanID,
+		SenderType: sender,
+		Price:      model.NewMoney(0, creator.Currency),
+		CreatedAt:  now,
+	}
+
+	if sender == model.AuthorFan {
+		message.Text = pickTemplate(fanMessageTemplates, creator.Category, s.rng)
+		return message
+	}
+
+	message.Text = pickTemplate(creatorMessageTemplates, creator.Category, s.rng)
+
+	// 30% of creator messages carry paid media, priced like locked content
+	if s.rng.Float64() < 0.3 {
+		mediaType := []string{"image", "video", "gallery"}[s.rng.Intn(3)]
+		message.MediaURL = fmt.Sprintf("https://cdn.platform.com/messages/%s.%s", message.ID, getFileExtension(mediaType))
+		message.IsLocked = true
+		message.Price = s.localPrice(creatorIndex, float64(s.rng.Intn(25)+5)) // $5.99-$29.99 equivalent
+		s.offerUnlock(creatorIndex, message.ID, fanID, message.Price)
+	}
+
+	return message
+}
+
+// Category-aware text templates, with a "" entry used for other categories
+var fanCommentTemplates = map[string][]string{
+	"fitness":   {"Those gains though 💪", "What's your leg day routine?", "Motivating as always!"},
+	"lifestyle": {"Love this vibe", "Where was this taken?", "Living the dream ✨"},
+	"art":       {"The colors are amazing", "How long did this take?", "Would buy a print!"},
+	"music":     {"On repeat all day 🎶", "When's the full release?", "That bridge is 🔥"},
+	"gaming":    {"GG!", "What settings do you use?", "That play was insane"},
+	"":          {"Love this!", "Amazing content 😍", "More like this please", "First!"},
+}
+
+var creatorReplyTemplates = map[string][]string{
+	"fitness": {"Thanks! Full routine coming soon 💪", "Consistency is everything!"},
+	"music":   {"Thank you! Full track drops Friday", "So glad you like it 🎶"},
+	"gaming":  {"GG 😎", "Settings video coming next week"},
+	"":        {"Thank you so much ❤️", "Glad you enjoyed it!", "More coming soon 😘"},
+}
+
+var fanMessageTemplates = map[string][]string{
+	"fitness": {"Can you make a custom workout plan?", "What do you eat before training?"},
+	"art":     {"Do you take commissions?", "Can I use this as a wallpaper?"},
+	"music":   {"Can you play my favorite song on stream?", "Any tour dates soon?"},
+	"cooking": {"Can you share the full recipe?", "What knife do you use?"},
+	"":        {"Hey! Big fan of your work", "Loved your last post", "Any exclusive content coming?"},
+}
+
+var creatorMessageTemplates = map[string][]string{
+	"fitness": {"Here's a bonus workout just for you", "Thanks for the support! 💪"},
+	"cooking": {"Full recipe video attached 😋", "Thanks for cooking along!"},
+	"":        {"Thanks for subscribing ❤️", "Here's something special for you", "Hope you enjoy this one 😘"},
+}
+
+// pickTemplate picks a random template for the category, falling back to the default set
+func pickTemplate(templates map[string][]string, category string, rng *rand.Rand) string {
+	options, exists := templates[category]
+	if !exists {
+		options = templates[""]
+	}
+	return options[rng.Intn(len(options))]
+}
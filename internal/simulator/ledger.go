@@ -0,0 +1,200 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// creatorLedger accumulates a creator's earnings in integer minor units of the
+// creator's currency, so totals balance exactly
+type creatorLedger struct {
+	grossMinor       int64
+	feeMinor         int64
+	netMinor         int64
+	paidOutMinor     int64
+	transactionCount int
+	unsettledCount   int // Transactions since the last payout
+	payoutCount      int
+}
+
+// unlockOffer is paid content or media a fan can still unlock
+type unlockOffer struct {
+	creatorIndex int
+	referenceID  string
+	fanID        string // Set for direct messages, which only their recipient can unlock
+	price        model.Money
+	expiresAt    time.Time
+}
+
+// PaymentEvents holds the payment events generated in one cycle
+type PaymentEvents struct {
+	Transactions []model.Transaction
+	Payouts      []model.Payout
+	Balances     []model.BalanceSnapshot
+}
+
+// GeneratePayments generates subscription and unlock payments, collects tips
+// recorded earlier in the cycle, books due refunds and chargebacks and, once per
+// payout interval, pays out every positive creator balance and publishes a
+// balance snapshot per creator. Reversals after a payout leave a negative
+// balance that is recovered from later earnings.
+func (s *PlatformSimulator) GeneratePayments() PaymentEvents {
+	now := time.Now()
+
+	// New subscriptions, more likely for popular creators and doubled during promotions
+	for i, creator := range s.creators {
+		if creator.IsSuspended {
+			continue
+		}
+		probability := float64(creator.SubscriberCount) / 20000
+		if creator.Promotion.IsActive(now) {
+			probability *= 2
+		}
+		if s.rng.Float64() < clamp(probability, 0.01, 0.5) {
+			s.recordSubscription(i, now)
+		}
+	}
+
+	// Fans unlock locked posts and paid messages
+	open := s.unlockOffers[:0]
+	for _, offer := range s.unlockOffers {
+		if now.After(offer.expiresAt) {
+			continue
+		}
+
+		if s.rng.Float64() < 0.05 {
+			fanID := offer.fanID
+			if fanID == "" {
+				fanID = s.randomFanID()
+			}
+			s.recordTransaction(offer.creatorIndex, model.TransactionUnlock, fanID, offer.referenceID, offer.price)
+
+			// A direct message can only be unlocked once
+			if offer.fanID != "" {
+				continue
+			}
+		}
+		open = append(open, offer)
+	}
+	s.unlockOffers = open
+
+	// Refunds and chargebacks that have come due
+	s.processReversals(now)
+
+	events := PaymentEvents{Transactions: s.pendingTransactions}
+	s.pendingTransactions = nil
+
+	if now.Sub(s.lastPayout) >= s.payoutInterval {
+		events.Payouts, events.Balances = s.settlePayouts(now)
+	}
+
+	return events
+}
+
+// offerUnlock makes locked content or paid media purchasable for a while
+func (s *PlatformSimulator) offerUnlock(creatorIndex int, referenceID, fanID string, price model.Money) {
+	s.unlockOffers = append(s.unlockOffers, &unlockOffer{
+		creatorIndex: creatorIndex,
+		referenceID:  referenceID,
+		fanID:        fanID,
+		price:        price,
+		expiresAt:    time.Now().Add(10 * time.Minute),
+	})
+}
+
+// recordTransaction books a payment to a creator's ledger, net of the platform
+// fee, and may schedule a later refund or chargeback for it
+func (s *PlatformSimulator) recordTransaction(creatorIndex int, txType, fanID, referenceID string, amount model.Money) model.Transaction {
+	return s.recordPayment(creatorIndex, model.Transaction{
+		Type:        txType,
+		FanID:       fanID,
+		ReferenceID: referenceID,
+	}, amount)
+}
+
+// recordPayment books tx for the given amount, like recordTransaction
+func (s *PlatformSimulator) recordPayment(creatorIndex int, tx model.Transaction, amount model.Money) model.Transaction {
+	grossMinor := amount.AmountMinor
+	feeMinor := int64(math.Round(float64(grossMinor) * s.platformFee))
+
+	tx = s.bookTransaction(creatorIndex, tx, grossMinor, feeMinor)
+
+	// Free trials have nothing to refund
+	if grossMinor > 0 {
+		s.maybeScheduleReversal(creatorIndex, tx, grossMinor, feeMinor)
+	}
+	return tx
+}
+
+// bookTransaction assigns an ID to tx, applies its amounts to the creator's
+// ledger and queues it for publishing. Negative amounts reverse earlier payments.
+func (s *PlatformSimulator) bookTransaction(creatorIndex int, tx model.Transaction, grossMinor, feeMinor int64) model.Transaction {
+	netMinor := grossMinor - feeMinor
+
+	ledger := &s.ledgers[creatorIndex]
+	ledger.grossMinor += grossMinor
+	ledger.feeMinor += feeMinor
+	ledger.netMinor += netMinor
+	ledger.transactionCount++
+	ledger.unsettledCount++
+
+	s.transactionCount++
+	tx.ID = fmt.Sprintf("tx-%d", s.transactionCount)
+	tx.CreatorID = s.creators[creatorIndex].ID
+	tx.FanCountry = s.fanCountry(tx.FanID)
+	currency := s.creatorCurrency(creatorIndex)
+	tx.Gross = model.NewMoney(grossMinor, currency)
+	tx.PlatformFee = model.NewMoney(feeMinor, currency)
+	tx.Net = model.NewMoney(netMinor, currency)
+	tx.FanFlagged = s.fraudFans[tx.FanID]
+	tx.CreatedAt = time.Now()
+
+	s.pendingTransactions = append(s.pendingTransactions, tx)
+	return tx
+}
+
+// settlePayouts pays out every positive balance and snapshots all ledgers
+func (s *PlatformSimulator) settlePayouts(now time.Time) ([]model.Payout, []model.BalanceSnapshot) {
+	var payouts []model.Payout
+	snapshots := make([]model.BalanceSnapshot, 0, len(s.creators))
+
+	for i, creator := range s.creators {
+		ledger := &s.ledgers[i]
+
+		// Suspended creators' balances are held until they are reinstated
+		balance := ledger.netMinor - ledger.paidOutMinor
+		if balance > 0 && !creator.IsSuspended {
+			s.payoutCount++
+			payouts = append(payouts, model.Payout{
+				ID:               fmt.Sprintf("payout-%d", s.payoutCount),
+				CreatorID:        creator.ID,
+				Amount:           model.NewMoney(balance, creator.Currency),
+				TransactionCount: ledger.unsettledCount,
+				PeriodStart:      s.lastPayout,
+				PeriodEnd:        now,
+				CreatedAt:        now,
+			})
+			ledger.paidOutMinor += balance
+			ledger.unsettledCount = 0
+			ledger.payoutCount++
+		}
+
+		snapshots = append(snapshots, model.BalanceSnapshot{
+			CreatorID:        creator.ID,
+			Gross:            model.NewMoney(ledger.grossMinor, creator.Currency),
+			PlatformFees:     model.NewMoney(ledger.feeMinor, creator.Currency),
+			Net:              model.NewMoney(ledger.netMinor, creator.Currency),
+			PaidOut:          model.NewMoney(ledger.paidOutMinor, creator.Currency),
+			Balance:          model.NewMoney(ledger.netMinor-ledger.paidOutMinor, creator.Currency),
+			TransactionCount: ledger.transactionCount,
+			PayoutCount:      ledger.payoutCount,
+			AsOf:             now,
+		})
+	}
+
+	s.lastPayout = now
+	return payouts, snapshots
+}
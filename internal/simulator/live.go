@@ -0,0 +1,170 @@
+package simulator
+
+import (
+	"fmt"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// Tip amounts fans can send during a live stream, in USD
+var liveTipAmounts = []float64{1, 5, 10, 20, 50, 100}
+
+// liveSession tracks an ongoing live stream
+type liveSession struct {
+	id            string
+	creatorIndex  int
+	startedAt     time.Time
+	viewers       int
+	targetViewers int // Audience the stream drifts towards
+	peakViewers   int
+	totalTips     model.Money
+}
+
+// GenerateLiveEvents starts, advances and ends live streaming sessions.
+// Only online creators can go live, and sessions end when the creator goes offline.
+func (s *PlatformSimulator) GenerateLiveEvents() ([]model.LiveEvent, []model.Content) {
+	var events []model.LiveEvent
+	var replays []model.Content
+
+	for i, creator := range s.creators {
+		session, live := s.liveSessions[i]
+
+		if !live {
+			if creator.IsOnline && s.rng.Float64() < s.liveStartProbability {
+				events = append(events, s.startLiveSession(i))
+			}
+			continue
+		}
+
+		// End the stream when the creator drops offline or randomly after a while
+		elapsed := time.Since(session.startedAt)
+		if !creator.IsOnline || (elapsed > time.Minute && s.rng.Float64() < 0.05) {
+			event, replay := s.endLiveSession(session)
+			events = append(events, event)
+			replays = append(replays, replay)
+			continue
+		}
+
+		events = append(events, s.advanceLiveSession(session)...)
+	}
+
+	return events, replays
+}
+
+// startLiveSession opens a new live session for a creator
+func (s *PlatformSimulator) startLiveSession(creatorIndex int) model.LiveEvent {
+	creator := s.creators[creatorIndex]
+	s.liveCounts[creatorIndex]++
+
+	// Live audiences are a fraction of the engaged subscriber base
+	target := int(float64(creator.SubscriberCount) * s.engagementRates[creatorIndex] * (0.2 + s.rng.Float64()*0.6))
+	if target < 1 {
+		target = 1
+	}
+
+	session := &liveSession{
+		id:            fmt.Sprintf("live-%s-%d", creator.ID, s.liveCounts[creatorIndex]),
+		creatorIndex:  creatorIndex,
+		startedAt:     time.Now(),
+		viewers:       1 + s.rng.Intn(target),
+		targetViewers: target,
+		totalTips:     model.NewMoney(0, creator.Currency),
+	}
+	session.peakViewers = session.viewers
+	s.liveSessions[creatorIndex] = session
+
+	return model.LiveEvent{
+		EventType:   model.LiveStarted,
+		SessionID:   session.id,
+		CreatorID:   creator.ID,
+		Timestamp:   session.startedAt,
+		Title:       generateContentTitle("live", creator.Category, s.rng),
+		ViewerCount: session.viewers,
+	}
+}
+
+// advanceLiveSession emits a viewer count heartbeat and any tips for this cycle
+func (s *PlatformSimulator) advanceLiveSession(session *liveSession) []model.LiveEvent {
+	creator := s.creators[session.creatorIndex]
+	now := time.Now()
+
+	// Viewers drift towards the target audience with some noise
+	drift := float64(session.targetViewers-session.viewers) * 0.3
+	noise := (s.rng.Float64() - 0.5) * 0.1 * float64(session.viewers)
+	session.viewers = int(clamp(float64(session.viewers)+drift+noise, 0, float64(creator.SubscriberCount)+1))
+	if session.viewers > session.peakViewers {
+		session.peakViewers = session.viewers
+	}
+
+	events := []model.LiveEvent{{
+		EventType:   model.LiveViewerCount,
+		SessionID:   session.id,
+		CreatorID:   creator.ID,
+		Timestamp:   now,
+		ViewerCount: session.viewers,
+		PeakViewers: session.peakViewers,
+	}}
+
+	// Roughly 1% of viewers tip per cycle, capped to keep bursts readable
+	numTips := 0
+	for v := 0; v < session.viewers && v < 500; v++ {
+		if s.rng.Float64() < 0.01 {
+			numTips++
+		}
+	}
+	for t := 0; t < numTips; t++ {
+		amount := s.localAmount(session.creatorIndex, liveTipAmounts[s.rng.Intn(len(liveTipAmounts))])
+		session.totalTips = session.totalTips.Add(amount)
+		tx := s.recordTransaction(session.creatorIndex, model.TransactionTip, s.randomFanID(), session.id, amount)
+		events = append(events, model.LiveEvent{
+			EventType:     model.LiveTip,
+			SessionID:     session.id,
+			CreatorID:     creator.ID,
+			Timestamp:     now,
+			ViewerCount:   session.viewers,
+			FanID:         tx.FanID,
+			TipAmount:     &amount,
+			TransactionID: tx.ID,
+		})
+	}
+
+	return events
+}
+
+// endLiveSession closes a live session and returns its replay as content
+func (s *PlatformSimulator) endLiveSession(session *liveSession) (model.LiveEvent, model.Content) {
+	creator := s.creators[session.creatorIndex]
+	now := time.Now()
+	delete(s.liveSessions, session.creatorIndex)
+
+	event := model.LiveEvent{
+		EventType:       model.LiveEnded,
+		SessionID:       session.id,
+		CreatorID:       creator.ID,
+		Timestamp:       now,
+		ViewerCount:     session.viewers,
+		PeakViewers:     session.peakViewers,
+		TotalTips:       &session.totalTips,
+		DurationSeconds: int(now.Sub(session.startedAt).Seconds()),
+	}
+
+	// The recording is published as regular "live" content
+	contentID := fmt.Sprintf("content-%s-%d", creator.ID, s.contentCounts[session.creatorIndex])
+	s.contentCounts[session.creatorIndex]++
+	replay := model.Content{
+		ID:          contentID,
+		CreatorID:   creator.ID,
+		Title:       generateContentTitle("live", creator.Category, s.rng) + " (Replay)",
+		ContentType: "live",
+		MediaURL:    fmt.Sprintf("https://cdn.platform.com/live/%s.%s", session.id, getFileExtension("live")),
+		Price:       model.NewMoney(0, creator.Currency),
+		ViewCount:   session.peakViewers,
+		LikeCount:   int(float64(session.peakViewers) * (0.1 + s.rng.Float64()*0.2)),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Tags:        []string{creator.Category, "live", "replay"},
+	}
+
+	return event, replay
+}
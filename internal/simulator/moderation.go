@@ -0,0 +1,161 @@
+package simulator
+
+import (
+	"fmt"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// pendingReport is a report waiting for a moderation decision
+type pendingReport struct {
+	report       model.Report
+	creatorIndex int
+	decideAt     time.Time
+}
+
+// ModerationEvents holds the trust and safety events generated in one cycle
+type ModerationEvents struct {
+	Reports        []model.Report
+	Decisions      []model.ModerationDecision
+	RemovedContent []model.RemovedContent // Content to tombstone
+}
+
+// GenerateModerationEvents files new reports, decides pending ones and
+// reinstates creators whose suspension has ended. Suspended creators are taken
+// offline and published with their next creator update.
+func (s *PlatformSimulator) GenerateModerationEvents() ModerationEvents {
+	var events ModerationEvents
+	now := time.Now()
+
+	// Fans report recent content
+	for _, post := range s.recentContent {
+		if s.rng.Float64() < s.reportProbability {
+			events.Reports = append(events.Reports,
+				s.fileReport(post.creatorIndex, model.TargetContent, post.content.ID, now))
+		}
+	}
+
+	// Creators are reported less often than individual posts
+	for i, creator := range s.creators {
+		if !creator.IsSuspended && s.rng.Float64() < s.reportProbability/5 {
+			events.Reports = append(events.Reports, s.fileReport(i, model.TargetCreator, creator.ID, now))
+		}
+	}
+
+	// Decide reports whose review time has come
+	waiting := s.pendingReports[:0]
+	for _, pending := range s.pendingReports {
+		if now.Before(pending.decideAt) {
+			waiting = append(waiting, pending)
+			continue
+		}
+
+		decision := s.decideReport(pending, now)
+		events.Decisions = append(events.Decisions, decision)
+
+		// Content that has aged out of the engagement window is still tombstoned
+		if decision.Decision != model.DecisionApproved && decision.TargetType == model.TargetContent {
+			s.removeContent(decision.TargetID)
+			events.RemovedContent = append(events.RemovedContent, model.RemovedContent{
+				ContentID: decision.TargetID,
+				CreatorID: decision.CreatorID,
+			})
+		}
+		if decision.Decision == model.DecisionCreatorSuspended {
+			s.suspendCreator(pending.creatorIndex, now)
+		}
+	}
+	s.pendingReports = waiting
+
+	// Reinstate creators whose suspension is over
+	for i := range s.creators {
+		if s.creators[i].IsSuspended && now.After(s.suspendedUntil[i]) {
+			s.creators[i].IsSuspended = false
+			s.pendingUpdates[i] = true
+		}
+	}
+
+	return events
+}
+
+// fileReport creates a report and queues it for review
+func (s *PlatformSimulator) fileReport(creatorIndex int, targetType, targetID string, now time.Time) model.Report {
+	s.reportCount++
+	report := model.Report{
+		ID:         fmt.Sprintf("report-%d", s.reportCount),
+		ReporterID: s.randomFanID(),
+		TargetType: targetType,
+		TargetID:   targetID,
+		CreatorID:  s.creators[creatorIndex].ID,
+		Reason:     model.ReportReasons[s.rng.Intn(len(model.ReportReasons))],
+		CreatedAt:  now,
+	}
+
+	// Moderators take between 10 seconds and 2 minutes to review a report
+	s.pendingReports = append(s.pendingReports, &pendingReport{
+		report:       report,
+		creatorIndex: creatorIndex,
+		decideAt:     now.Add(time.Duration(10+s.rng.Intn(110)) * time.Second),
+	})
+
+	return report
+}
+
+// decideReport reviews a report; most reports are approved with no action
+func (s *PlatformSimulator) decideReport(pending *pendingReport, now time.Time) model.ModerationDecision {
+	report := pending.report
+	roll := s.rng.Float64()
+
+	decision := model.DecisionApproved
+	switch report.TargetType {
+	case model.TargetContent:
+		if roll < 0.05 {
+			decision = model.DecisionCreatorSuspended // Content is removed as well
+		} else if roll < 0.25 {
+			decision = model.DecisionRemoved
+		}
+	case model.TargetCreator:
+		if roll < 0.15 {
+			decision = model.DecisionCreatorSuspended
+		}
+	}
+
+	s.decisionCount++
+	return model.ModerationDecision{
+		ID:          fmt.Sprintf("decision-%d", s.decisionCount),
+		ReportID:    report.ID,
+		TargetType:  report.TargetType,
+		TargetID:    report.TargetID,
+		CreatorID:   report.CreatorID,
+		Decision:    decision,
+		Reason:      report.Reason,
+		ModeratorID: fmt.Sprintf("moderator-%d", s.rng.Intn(20)),
+		DecidedAt:   now,
+	}
+}
+
+// removeContent stops removed content from receiving engagement
+func (s *PlatformSimulator) removeContent(contentID string) {
+	for i, post := range s.recentContent {
+		if post.content.ID == contentID {
+			s.recentContent = append(s.recentContent[:i], s.recentContent[i+1:]...)
+			break
+		}
+	}
+
+	for i, post := range s.viralPosts {
+		if post.content.ID == contentID {
+			s.viralPosts = append(s.viralPosts[:i], s.viralPosts[i+1:]...)
+			break
+		}
+	}
+}
+
+// suspendCreator takes a creator offline for 5-15 minutes
+func (s *PlatformSimulator) suspendCreator(creatorIndex int, now time.Time) {
+	s.creators[creatorIndex].IsSuspended = true
+	s.creators[creatorIndex].IsOnline = false
+	s.suspendedUntil[creatorIndex] = now.Add(time.Duration(5+s.rng.Intn(11)) * time.Minute)
+	s.pendingUpdates[creatorIndex] = true
+}
@@ -2,8 +2,10 @@ package simulator
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"onlyfans-event-publisher/internal/model"
+	"sort"
 	"time"
 )
 
@@ -15,13 +17,118 @@ type PlatformSimulator struct {
 	lastPostTimes        []time.Time
 	subscriberTrends     []float64 // Subscriber growth trend
 	engagementRates      []float64 // Base engagement rate per creator
+	updateProbs          []float64 // Per-cycle profile update chance, weighted by popularity
+	pendingUpdates       []bool    // Creators whose profile changed outside a regular update
+	viralPosts           []*viralPost
+	viralProbability     float64
+	liveSessions         map[int]*liveSession // Ongoing live streams by creator index
+	liveCounts           []int                // Number of live sessions per creator
+	liveStartProbability float64
+	recentContent        []*recentPost // Recently published content open for comments
+	dmThreads            map[string]*dmThread
+	dmOrder              []string // Thread IDs in creation order
+	commentCount         int
+	messageCount         int
+	commentProbability   float64
+	messageProbability   float64
+	pendingReports       []*pendingReport
+	suspendedUntil       []time.Time
+	reportCount          int
+	decisionCount        int
+	reportProbability    float64
+	ledgers              []creatorLedger
+	unlockOffers         []*unlockOffer
+	pendingTransactions  []model.Transaction // Booked this cycle, published by GeneratePayments
+	transactionCount     int
+	payoutCount          int
+	lastPayout           time.Time
+	platformFee          float64
+	payoutInterval       time.Duration
+	pendingReversals     []*pendingReversal
+	fraudFans            map[string]bool // Fans flagged as fraud risks
+	fraudFanIDs          []string
+	fraudRates           FraudRates
+	fraudLabelCount      int
+	promotionCount       int
+	promotionProbability float64
+	exchangeRates        map[string]float64 // Value of one USD in each currency
+	fanCountries         CountryWeights
+	lastExchangeRates    time.Time
+	exchangeRateCount    int
+	exchangeRateInterval time.Duration
+	refundRate           float64
+	chargebackRate       float64
 	abnormalActivityProb float64
 	rng                  *rand.Rand
 }
 
+// Options configures a PlatformSimulator
+type Options struct {
+	NumCreators          int
+	AbnormalProbability  float64
+	ViralProbability     float64 // Chance that a new post goes viral
+	LiveProbability      float64 // Chance per cycle that an online creator goes live
+	CommentProbability   float64 // Chance per cycle that a recent post gets a comment
+	MessageProbability   float64 // Chance per cycle that a creator gets, or answers, a direct message
+	ReportProbability    float64 // Chance per cycle that a recent post is reported
+	PlatformFee          float64 // Fraction of every payment kept by the platform
+	PayoutInterval       time.Duration
+	RefundRate           float64 // Fraction of payments refunded
+	ChargebackRate       float64 // Base fraction of payments charged back
+	FraudFanFraction     float64 // Fraction of fans flagged as fraud risks
+	Fraud                FraudRates
+	PromotionProbability float64 // Chance per cycle that a creator starts a promotion
+
+	// Countries creators and fans are drawn from; empty weights place everyone in the US
+	CreatorCountries     CountryWeights
+	FanCountries         CountryWeights
+	ExchangeRateInterval time.Duration
+
+	// Distributions used to initialize creators; nil uses the defaults below
+	SubscriberDistribution Distribution
+	ActivityDistribution   Distribution
+	EngagementDistribution Distribution
+}
+
+// Default creator distributions
+var (
+	DefaultSubscriberDistribution Distribution = UniformDistribution{Min: 100, Max: 10100} // 100-10,100 subscribers
+	DefaultActivityDistribution   Distribution = UniformDistribution{Min: 0.2, Max: 1.0}   // 0.2-1.0 activity level
+	DefaultEngagementDistribution Distribution = UniformDistribution{Min: 0.05, Max: 0.2}  // 5%-20% engagement rate
+)
+
+// baseUpdateProbability is the average chance of a creator update per cycle
+const baseUpdateProbability = 0.2
+
+// fanPoolSize is the number of distinct simulated fans
+const fanPoolSize = 100000
+
 // NewPlatformSimulator creates a new platform simulator
-func NewPlatformSimulator(numCreators int, abnormalProb float64) *PlatformSimulator {
+func NewPlatformSimulator(opts Options) *PlatformSimulator {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	numCreators := opts.NumCreators
+
+	subscriberDist := opts.SubscriberDistribution
+	if subscriberDist == nil {
+		subscriberDist = DefaultSubscriberDistribution
+	}
+	activityDist := opts.ActivityDistribution
+	if activityDist == nil {
+		activityDist = DefaultActivityDistribution
+	}
+	engagementDist := opts.EngagementDistribution
+	if engagementDist == nil {
+		engagementDist = DefaultEngagementDistribution
+	}
+	creatorCountries := opts.CreatorCountries
+	if len(creatorCountries.countries) == 0 {
+		creatorCountries = DefaultCountryWeights
+	}
+	fanCountries := opts.FanCountries
+	if len(fanCountries.countries) == 0 {
+		fanCountries = DefaultCountryWeights
+	}
+	exchangeRates := newExchangeRates()
 
 	// Create creators
 	creators := make([]model.Creator, numCreators)
@@ -30,13 +137,16 @@ func NewPlatformSimulator(numCreators int, abnormalProb float64) *PlatformSimula
 	lastPostTimes := make([]time.Time, numCreators)
 	subscriberTrends := make([]float64, numCreators)
 	engagementRates := make([]float64, numCreators)
+	updateProbs := make([]float64, numCreators)
 
 	baseTime := time.Now().Add(-time.Hour * 24 * 30) // Start 30 days ago
 
 	// Initialize creators with realistic data
 	for i := 0; i < numCreators; i++ {
-		subscriberCount := r.Intn(10000) + 100       // 100-10,100 subscribers
-		monthlyPrice := float64(r.Intn(45)+5) + 0.99 // $5.99-$49.99
+		subscriberCount := int(clamp(subscriberDist.Sample(r), 0, math.MaxInt32))
+		country := creatorCountries.pick(r.Float64())
+		currency := model.CountryCurrencies[country]
+		monthlyPrice := charmPrice(float64(r.Intn(45)+5)*exchangeRates[currency], currency) // $5.99-$49.99 equivalent
 
 		creators[i] = model.Creator{
 			ID:              fmt.Sprintf("creator-%d", i),
@@ -45,19 +155,47 @@ func NewPlatformSimulator(numCreators int, abnormalProb float64) *PlatformSimula
 			Email:           fmt.Sprintf("creator%d@platform.com", i),
 			IsVerified:      r.Float64() < 0.3, // 30% verified
 			SubscriberCount: subscriberCount,
-			MonthlyPrice:    monthlyPrice,
+			Currency:        currency,
+			Country:         country,
 			CreatedAt:       baseTime.Add(time.Duration(r.Intn(720)) * time.Hour), // Random creation time
 			IsOnline:        r.Float64() < 0.4,                                    // 40% online initially
 			Category:        model.CreatorCategories[r.Intn(len(model.CreatorCategories))],
 			ProfilePic:      fmt.Sprintf("https://cdn.platform.com/profiles/creator-%d.jpg", i),
+			Bundles:         newBundles(r),
 		}
+		creators[i].UpdatedAt = creators[i].CreatedAt
+		setMonthlyPrice(&creators[i], monthlyPrice)
 
 		// Initialize activity patterns
-		activityLevels[i] = r.Float64()*0.8 + 0.2 // 0.2-1.0 activity level
+		activityLevels[i] = clamp(activityDist.Sample(r), 0, 1)
 		lastPostTimes[i] = time.Now().Add(-time.Duration(r.Intn(48)) * time.Hour)
 		subscriberTrends[i] = (r.Float64() - 0.5) * 0.02 // -1% to +1% daily trend
-		engagementRates[i] = r.Float64()*0.15 + 0.05     // 5%-20% engagement rate
-		contentCounts[i] = r.Intn(50) + 10               // Start with 10-60 posts
+		engagementRates[i] = clamp(engagementDist.Sample(r), 0, 1)
+		contentCounts[i] = r.Intn(50) + 10 // Start with 10-60 posts
+	}
+
+	fraudFans := newFraudFans(opts.FraudFanFraction)
+	fraudFanIDs := make([]string, 0, len(fraudFans))
+	for fanID := range fraudFans {
+		fraudFanIDs = append(fraudFanIDs, fanID)
+	}
+	sort.Strings(fraudFanIDs) // Map order is random; keep picks reproducible
+
+	// Under skewed subscriber distributions popular creators get proportionally
+	// more profile updates, producing hot Creator.ID keys. Others, the uniform
+	// default included, keep the same chance for every creator.
+	skewed := subscriberDist.Skewed()
+	totalSubscribers := 0
+	for _, creator := range creators {
+		totalSubscribers += creator.SubscriberCount
+	}
+	for i, creator := range creators {
+		if !skewed || totalSubscribers == 0 {
+			updateProbs[i] = baseUpdateProbability
+			continue
+		}
+		share := float64(creator.SubscriberCount) / float64(totalSubscribers)
+		updateProbs[i] = clamp(baseUpdateProbability*share*float64(numCreators), 0.01, 1)
 	}
 
 	return &PlatformSimulator{
@@ -67,7 +205,31 @@ func NewPlatformSimulator(numCreators int, abnormalProb float64) *PlatformSimula
 		lastPostTimes:        lastPostTimes,
 		subscriberTrends:     subscriberTrends,
 		engagementRates:      engagementRates,
-		abnormalActivityProb: abnormalProb,
+		updateProbs:          updateProbs,
+		pendingUpdates:       make([]bool, numCreators),
+		viralProbability:     opts.ViralProbability,
+		liveSessions:         make(map[int]*liveSession),
+		liveCounts:           make([]int, numCreators),
+		liveStartProbability: opts.LiveProbability,
+		dmThreads:            make(map[string]*dmThread),
+		commentProbability:   opts.CommentProbability,
+		messageProbability:   opts.MessageProbability,
+		suspendedUntil:       make([]time.Time, numCreators),
+		reportProbability:    opts.ReportProbability,
+		ledgers:              make([]creatorLedger, numCreators),
+		lastPayout:           time.Now(),
+		platformFee:          opts.PlatformFee,
+		payoutInterval:       opts.PayoutInterval,
+		fraudFans:            fraudFans,
+		fraudFanIDs:          fraudFanIDs,
+		fraudRates:           opts.Fraud,
+		promotionProbability: opts.PromotionProbability,
+		exchangeRates:        exchangeRates,
+		fanCountries:         fanCountries,
+		exchangeRateInterval: opts.ExchangeRateInterval,
+		refundRate:           opts.RefundRate,
+		chargebackRate:       opts.ChargebackRate,
+		abnormalActivityProb: opts.AbnormalProbability,
 		rng:                  r,
 	}
 }
@@ -82,9 +244,12 @@ func (s *PlatformSimulator) GenerateCreatorUpdates() []model.Creator {
 	var updates []model.Creator
 
 	for i := range s.creators {
-		// 20% chance of creator update per cycle
-		if s.rng.Float64() < 0.2 {
+		// 20% chance of creator update per cycle, or in proportion to subscriber
+		// share (at least 1%) under skewed distributions. Creators gaining
+		// subscribers from viral posts are always published.
+		if s.pendingUpdates[i] || s.rng.Float64() < s.updateProbs[i] {
 			updates = append(updates, s.generateCreatorUpdate(i))
+			s.pendingUpdates[i] = false
 		}
 	}
 
@@ -96,6 +261,11 @@ func (s *PlatformSimulator) GenerateContent() []model.Content {
 	var content []model.Content
 
 	for i := range s.creators {
+		// Suspended creators can't post
+		if s.creators[i].IsSuspended {
+			continue
+		}
+
 		// Check if creator should post based on activity level and time since last post
 		timeSincePost := time.Since(s.lastPostTimes[i])
 		shouldPost := s.shouldCreatorPost(i, timeSincePost)
@@ -103,6 +273,11 @@ func (s *PlatformSimulator) GenerateContent() []model.Content {
 		if shouldPost {
 			newContent := s.generateCreatorContent(i)
 			content = append(content, newContent)
+			s.maybeGoViral(i, newContent)
+			s.trackRecentContent(i, newContent)
+			if newContent.IsLocked {
+				s.offerUnlock(i, newContent.ID, "", newContent.Price)
+			}
 			s.lastPostTimes[i] = time.Now()
 			s.contentCounts[i]++
 		}
@@ -122,15 +297,16 @@ func (s *PlatformSimulator) generateCreatorUpdate(creatorIndex int) model.Creato
 		creator.SubscriberCount = 0
 	}
 
-	// Update online status (60% chance of change)
-	if s.rng.Float64() < 0.6 {
+	// Update online status (60% chance of change); suspended creators stay offline
+	if !creator.IsSuspended && s.rng.Float64() < 0.6 {
 		creator.IsOnline = !creator.IsOnline
 	}
 
 	// Occasionally adjust monthly price (5% chance)
 	if s.rng.Float64() < 0.05 {
-		priceChange := (s.rng.Float64() - 0.5) * 10 // ±$5 change
-		creator.MonthlyPrice = clamp(creator.MonthlyPrice+priceChange, 4.99, 99.99)
+		rate := s.exchangeRates[creator.Currency]
+		priceChange := (s.rng.Float64() - 0.5) * 10 * rate // ±$5 equivalent change
+		setMonthlyPrice(&creator, clamp(creator.MonthlyPrice.Float()+priceChange, 4.99*rate, 99.99*rate))
 	}
 
 	// Update trends occasionally
@@ -140,6 +316,7 @@ func (s *PlatformSimulator) generateCreatorUpdate(creatorIndex int) model.Creato
 	}
 
 	// Update the stored creator
+	creator.UpdatedAt = time.Now()
 	s.creators[creatorIndex] = creator
 	return creator
 }
@@ -173,18 +350,25 @@ func (s *PlatformSimulator) generateCreatorContent(creatorIndex int) model.Conte
 	creator := s.creators[creatorIndex]
 	contentID := fmt.Sprintf("content-%s-%d", creator.ID, s.contentCounts[creatorIndex])
 
+	// Live content only comes from live session replays
 	contentType := model.ContentTypes[s.rng.Intn(len(model.ContentTypes))]
+	for contentType == "live" {
+		contentType = model.ContentTypes[s.rng.Intn(len(model.ContentTypes))]
+	}
 
 	// Generate realistic engagement based on creator's subscriber count and engagement rate
 	baseViews := int(float64(creator.SubscriberCount) * s.engagementRates[creatorIndex])
-	viewCount := baseViews + s.rng.Intn(baseViews/2)                   // ±25% variation
+	viewCount := baseViews
+	if baseViews >= 2 {
+		viewCount += s.rng.Intn(baseViews / 2) // ±25% variation
+	}
 	likeCount := int(float64(viewCount) * (0.1 + s.rng.Float64()*0.2)) // 10-30% like rate
 
 	// Determine if content should be locked/premium
 	isLocked := s.rng.Float64() < 0.4 // 40% premium content
-	var price float64
+	price := model.NewMoney(0, creator.Currency)
 	if isLocked {
-		price = float64(s.rng.Intn(25)+5) + 0.99 // $5.99-$29.99 for premium
+		price = s.localPrice(creatorIndex, float64(s.rng.Intn(25)+5)) // $5.99-$29.99 equivalent for premium
 	}
 
 	// Generate media URL based on content type
@@ -197,6 +381,7 @@ func (s *PlatformSimulator) generateCreatorContent(creatorIndex int) model.Conte
 	// Generate tags
 	tags := generateTags(creator.Category, contentType, s.rng)
 
+	now := time.Now()
 	return model.Content{
 		ID:          contentID,
 		CreatorID:   creator.ID,
@@ -208,12 +393,17 @@ func (s *PlatformSimulator) generateCreatorContent(creatorIndex int) model.Conte
 		IsLocked:    isLocked,
 		ViewCount:   viewCount,
 		LikeCount:   likeCount,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		CreatedAt:   now,
+		UpdatedAt:   now, // Updates move it on, so consumers can tell new posts apart
 		Tags:        tags,
 	}
 }
 
+// randomFanID picks a fan from the simulated fan pool
+func (s *PlatformSimulator) randomFanID() string {
+	return fmt.Sprintf("fan-%d", s.rng.Intn(fanPoolSize))
+}
+
 // Helper functions
 func getFileExtension(contentType string) string {
 	switch contentType {
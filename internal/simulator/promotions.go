@@ -0,0 +1,106 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// Free trial lengths creators can offer
+var freeTrialDays = []int{3, 7, 30}
+
+// newBundles offers multi-month discounts to about half of the creators
+func newBundles(rng *rand.Rand) []model.Bundle {
+	if rng.Float64() < 0.5 {
+		return nil
+	}
+	return []model.Bundle{
+		{Months: 3, DiscountPercent: 10 + rng.Intn(11)},  // 10-20% off
+		{Months: 6, DiscountPercent: 20 + rng.Intn(11)},  // 20-30% off
+		{Months: 12, DiscountPercent: 30 + rng.Intn(11)}, // 30-40% off
+	}
+}
+
+// GeneratePromotions starts and ends time-limited creator promotions.
+// Creators are published with their next creator update whenever a promotion
+// starts or ends, so profiles always show the current offer.
+func (s *PlatformSimulator) GeneratePromotions() []model.PromotionEvent {
+	var events []model.PromotionEvent
+	now := time.Now()
+
+	for i := range s.creators {
+		creator := &s.creators[i]
+
+		if creator.Promotion != nil {
+			if !now.Before(creator.Promotion.EndsAt) {
+				events = append(events, model.PromotionEvent{
+					EventType:    model.PromotionEnded,
+					CreatorID:    creator.ID,
+					Promotion:    *creator.Promotion,
+					MonthlyPrice: creator.MonthlyPrice,
+					Timestamp:    now,
+				})
+				creator.Promotion = nil
+				s.pendingUpdates[i] = true
+			}
+			continue
+		}
+
+		if creator.IsSuspended || s.rng.Float64() >= s.promotionProbability {
+			continue
+		}
+
+		s.promotionCount++
+		promotion := &model.Promotion{
+			ID:       fmt.Sprintf("promo-%d", s.promotionCount),
+			StartsAt: now,
+			EndsAt:   now.Add(time.Duration(2+s.rng.Intn(9)) * time.Minute), // Campaigns run 2-10 minutes
+		}
+		if s.rng.Float64() < 0.7 {
+			promotion.Type = model.PromotionPercentOff
+			promotion.PercentOff = 10 + 5*s.rng.Intn(9) // 10-50% off in steps of 5
+		} else {
+			promotion.Type = model.PromotionFreeTrial
+			promotion.FreeTrialDays = freeTrialDays[s.rng.Intn(len(freeTrialDays))]
+		}
+
+		creator.Promotion = promotion
+		s.pendingUpdates[i] = true
+		events = append(events, model.PromotionEvent{
+			EventType:    model.PromotionStarted,
+			CreatorID:    creator.ID,
+			Promotion:    *promotion,
+			MonthlyPrice: creator.MonthlyPrice,
+			Timestamp:    now,
+		})
+	}
+
+	return events
+}
+
+// recordSubscription sells a subscription at the creator's effective price.
+// Most fans buy a single month; some pick one of the creator's bundles.
+func (s *PlatformSimulator) recordSubscription(creatorIndex int, now time.Time) model.Transaction {
+	creator := s.creators[creatorIndex]
+
+	months := 1
+	if len(creator.Bundles) > 0 && s.rng.Float64() < 0.2 {
+		months = creator.Bundles[s.rng.Intn(len(creator.Bundles))].Months
+	}
+
+	tx := model.Transaction{
+		Type:        model.TransactionSubscription,
+		FanID:       s.randomFanID(),
+		ReferenceID: creator.ID,
+	}
+	if months > 1 {
+		tx.BundleMonths = months
+	}
+	if creator.Promotion.IsActive(now) {
+		tx.PromotionID = creator.Promotion.ID
+	}
+
+	return s.recordPayment(creatorIndex, tx, creator.EffectivePrice(months, now))
+}
@@ -0,0 +1,98 @@
+package simulator
+
+import (
+	"fmt"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// Flagged fraud fans charge back far more often than everyone else
+const (
+	fraudChargebackMultiplier  = 40
+	normalChargebackMultiplier = 0.25
+)
+
+// pendingReversal is a refund or chargeback waiting to be booked
+type pendingReversal struct {
+	creatorIndex int
+	original     model.Transaction
+	txType       string
+	grossMinor   int64
+	feeMinor     int64
+	dueAt        time.Time
+}
+
+// newFraudFans flags a fraction of the fan pool as fraud risks
+func newFraudFans(fraction float64) map[string]bool {
+	fans := make(map[string]bool)
+	step := 0
+	if fraction > 0 {
+		step = int(1 / fraction)
+	}
+	// Every step-th fan is flagged so the set is stable across runs
+	for i := 0; step > 0 && i < fanPoolSize; i += step {
+		fans[fmt.Sprintf("fan-%d", i)] = true
+	}
+	return fans
+}
+
+// maybeScheduleReversal decides whether a payment will later be refunded or charged back
+func (s *PlatformSimulator) maybeScheduleReversal(creatorIndex int, tx model.Transaction, grossMinor, feeMinor int64) {
+	chargebackRate := s.chargebackRate * normalChargebackMultiplier
+	if s.fraudFans[tx.FanID] {
+		chargebackRate = clamp(s.chargebackRate*fraudChargebackMultiplier, 0, 1)
+	}
+
+	var txType string
+	var delay time.Duration
+	switch roll := s.rng.Float64(); {
+	case roll < chargebackRate:
+		// Chargebacks arrive after the bank dispute, 2-15 minutes in simulated time
+		txType = model.TransactionChargeback
+		delay = time.Duration(120+s.rng.Intn(780)) * time.Second
+	case roll < chargebackRate+s.refundRate:
+		// Refunds are requested fairly soon, within 30 seconds to 5 minutes
+		txType = model.TransactionRefund
+		delay = time.Duration(30+s.rng.Intn(270)) * time.Second
+	default:
+		return
+	}
+
+	s.pendingReversals = append(s.pendingReversals, &pendingReversal{
+		creatorIndex: creatorIndex,
+		original:     tx,
+		txType:       txType,
+		grossMinor:   grossMinor,
+		feeMinor:     feeMinor,
+		dueAt:        tx.CreatedAt.Add(delay),
+	})
+}
+
+// processReversals books every refund and chargeback that has come due
+func (s *PlatformSimulator) processReversals(now time.Time) {
+	waiting := s.pendingReversals[:0]
+
+	for _, reversal := range s.pendingReversals {
+		if now.Before(reversal.dueAt) {
+			waiting = append(waiting, reversal)
+			continue
+		}
+
+		reasons := model.RefundReasons
+		if reversal.txType == model.TransactionChargeback {
+			reasons = model.ChargebackReasons
+		}
+
+		// The platform returns its fee, so the creator loses exactly the original net
+		s.bookTransaction(reversal.creatorIndex, model.Transaction{
+			Type:                  reversal.txType,
+			FanID:                 reversal.original.FanID,
+			ReferenceID:           reversal.original.ReferenceID,
+			OriginalTransactionID: reversal.original.ID,
+			Reason:                reasons[s.rng.Intn(len(reasons))],
+		}, -reversal.grossMinor, -reversal.feeMinor)
+	}
+
+	s.pendingReversals = waiting
+}
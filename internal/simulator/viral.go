@@ -0,0 +1,76 @@
+package simulator
+
+import (
+	"math"
+	"time"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+// viralPost tracks a content item whose engagement is growing exponentially
+type viralPost struct {
+	content      model.Content
+	creatorIndex int
+	growthRate   float64 // Per-cycle view multiplier
+	likeRate     float64
+	conversion   float64 // Fraction of new views that turn into subscribers
+	cyclesLeft   int
+}
+
+// maybeGoViral starts a viral event for freshly generated content
+func (s *PlatformSimulator) maybeGoViral(creatorIndex int, content model.Content) {
+	if s.rng.Float64() >= s.viralProbability {
+		return
+	}
+
+	// Seed with at least a few hundred views so growth is visible for small creators
+	if content.ViewCount < 100 {
+		content.ViewCount = 100 + s.rng.Intn(400)
+	}
+
+	s.viralPosts = append(s.viralPosts, &viralPost{
+		content:      content,
+		creatorIndex: creatorIndex,
+		growthRate:   1.3 + s.rng.Float64()*0.5,     // 30%-80% more views per cycle
+		likeRate:     0.15 + s.rng.Float64()*0.15,   // 15-30% like rate
+		conversion:   0.005 + s.rng.Float64()*0.015, // 0.5%-2% of new viewers subscribe
+		cyclesLeft:   5 + s.rng.Intn(16),            // Trend lasts 5-20 cycles
+	})
+}
+
+// AdvanceViralContent grows every viral post by one cycle and returns the
+// updated content. Creators of viral posts gain subscribers, which are
+// published with their next creator update.
+func (s *PlatformSimulator) AdvanceViralContent() []model.Content {
+	var updates []model.Content
+	active := s.viralPosts[:0]
+
+	for _, post := range s.viralPosts {
+		// Growth slows down as the trend runs out
+		rate := 1 + (post.growthRate-1)*math.Min(1, float64(post.cyclesLeft)/5)
+		previousViews := post.content.ViewCount
+		post.content.ViewCount = int(float64(previousViews) * rate)
+		post.content.LikeCount = int(float64(post.content.ViewCount) * post.likeRate)
+		post.content.UpdatedAt = time.Now()
+		post.content.IsViral = true
+		if !contains(post.content.Tags, model.TagViral) {
+			post.content.Tags = append(post.content.Tags, model.TagViral)
+		}
+
+		newSubscribers := int(float64(post.content.ViewCount-previousViews) * post.conversion)
+		if newSubscribers > 0 {
+			s.creators[post.creatorIndex].SubscriberCount += newSubscribers
+			s.pendingUpdates[post.creatorIndex] = true
+		}
+
+		updates = append(updates, post.content)
+
+		post.cyclesLeft--
+		if post.cyclesLeft > 0 {
+			active = append(active, post)
+		}
+	}
+
+	s.viralPosts = active
+	return updates
+}
@@ -0,0 +1,213 @@
+package sink
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// File compression formats
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// FileOptions configures a File sink
+type FileOptions struct {
+	Dir         string
+	MaxBytes    int64         // Rotate once a file holds this many uncompressed bytes; 0 disables
+	MaxAge      time.Duration // Rotate files older than this; 0 disables
+	Compression string        // "none", "gzip" or "zstd"
+}
+
+// File writes each topic's records as newline-delimited JSON to its own file,
+// rotating by size and age. Each line is a record value; keys, headers and
+// tombstones have no place in NDJSON and are not written. Files are synced
+// to disk when rotated and on Close.
+type File struct {
+	opts FileOptions
+
+	mu     sync.Mutex
+	topics map[string]*topicFile
+	seq    int // Distinguishes files opened within the same second
+}
+
+// topicFile is the file currently being written for one topic
+type topicFile struct {
+	file     *os.File
+	closer   io.Closer // Compressor, if any
+	buffer   *bufio.Writer
+	written  int64 // Uncompressed bytes
+	openedAt time.Time
+}
+
+// NewFile creates the output directory and returns a File sink
+func NewFile(opts FileOptions) (*File, error) {
+	switch opts.Compression {
+	case "":
+		opts.Compression = CompressionNone
+	case CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		return nil, fmt.Errorf("unknown compression %q (expected %s, %s or %s)",
+			opts.Compression, CompressionNone, CompressionGzip, CompressionZstd)
+	}
+	if opts.MaxBytes < 0 || opts.MaxAge < 0 {
+		return nil, fmt.Errorf("rotation limits cannot be negative")
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return &File{opts: opts, topics: make(map[string]*topicFile)}, nil
+}
+
+// Write appends each record's value to its topic's file
+func (f *File) Write(ctx context.Context, records []*kgo.Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	touched := make(map[*topicFile]bool)
+	for _, record := range records {
+		if record.Value == nil {
+			continue
+		}
+
+		tf, err := f.fileFor(record.Topic, now)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tf.buffer.Write(record.Value); err != nil {
+			return fmt.Errorf("failed to write %s record: %w", record.Topic, err)
+		}
+		if err := tf.buffer.WriteByte('\n'); err != nil {
+			return fmt.Errorf("failed to write %s record: %w", record.Topic, err)
+		}
+		tf.written += int64(len(record.Value)) + 1
+		touched[tf] = true
+	}
+
+	// Hand each batch to the OS so readers see whole lines. Compressed data
+	// only becomes readable as the compressor emits blocks.
+	for tf := range touched {
+		if err := tf.buffer.Flush(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", tf.file.Name(), err)
+		}
+	}
+	return nil
+}
+
+// fileFor returns the open file for topic, rotating it first if it is full or
+// too old; f.mu must be held
+func (f *File) fileFor(topic string, now time.Time) (*topicFile, error) {
+	tf, ok := f.topics[topic]
+	if ok && !f.due(tf, now) {
+		return tf, nil
+	}
+
+	if ok {
+		if err := tf.close(); err != nil {
+			return nil, err
+		}
+		delete(f.topics, topic)
+	}
+
+	tf, err := f.open(topic, now)
+	if err != nil {
+		return nil, err
+	}
+	f.topics[topic] = tf
+	return tf, nil
+}
+
+// due reports whether tf should be rotated before the next write
+func (f *File) due(tf *topicFile, now time.Time) bool {
+	if f.opts.MaxBytes > 0 && tf.written >= f.opts.MaxBytes {
+		return true
+	}
+	return f.opts.MaxAge > 0 && now.Sub(tf.openedAt) >= f.opts.MaxAge
+}
+
+// open creates a new file for topic named after the time it was opened
+func (f *File) open(topic string, now time.Time) (*topicFile, error) {
+	f.seq++
+	name := fmt.Sprintf("%s-%s-%04d.ndjson", topic, now.UTC().Format("20060102T150405Z"), f.seq)
+	switch f.opts.Compression {
+	case CompressionGzip:
+		name += ".gz"
+	case CompressionZstd:
+		name += ".zst"
+	}
+
+	file, err := os.OpenFile(filepath.Join(f.opts.Dir, name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	tf := &topicFile{file: file, openedAt: now}
+	var w io.Writer = file
+	switch f.opts.Compression {
+	case CompressionGzip:
+		gz := gzip.NewWriter(file)
+		tf.closer, w = gz, gz
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+		}
+		tf.closer, w = zw, zw
+	}
+	tf.buffer = bufio.NewWriterSize(w, 64*1024)
+	return tf, nil
+}
+
+// close flushes, finishes compression, syncs and closes the file
+func (tf *topicFile) close() error {
+	name := tf.file.Name()
+	if err := tf.buffer.Flush(); err != nil {
+		tf.file.Close()
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if tf.closer != nil {
+		if err := tf.closer.Close(); err != nil {
+			tf.file.Close()
+			return fmt.Errorf("failed to finish compressing %s: %w", name, err)
+		}
+	}
+	if err := tf.file.Sync(); err != nil {
+		tf.file.Close()
+		return fmt.Errorf("failed to sync %s: %w", name, err)
+	}
+	if err := tf.file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", name, err)
+	}
+	return nil
+}
+
+// Close finishes every open file
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var firstErr error
+	for topic, tf := range f.topics {
+		if err := tf.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(f.topics, topic)
+	}
+	return firstErr
+}
@@ -0,0 +1,147 @@
+package sink
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// readNDJSON returns the lines of every file in dir whose name starts with
+// topic, decompressing by extension, in file name order
+func readNDJSON(t *testing.T, dir, topic string) (files int, lines []string) {
+	t.Helper()
+	names, err := filepath.Glob(filepath.Join(dir, topic+"-*"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatalf("open %s: %v", name, err)
+		}
+		var r io.Reader = file
+		switch filepath.Ext(name) {
+		case ".gz":
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				t.Fatalf("gzip %s: %v", name, err)
+			}
+			r = gz
+		case ".zst":
+			zr, err := zstd.NewReader(file)
+			if err != nil {
+				t.Fatalf("zstd %s: %v", name, err)
+			}
+			defer zr.Close()
+			r = zr
+		}
+		data, err := io.ReadAll(r)
+		file.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")...)
+	}
+	return len(names), lines
+}
+
+func TestFileWritesCompressedNDJSON(t *testing.T) {
+	for _, compression := range []string{CompressionNone, CompressionGzip, CompressionZstd} {
+		t.Run(compression, func(t *testing.T) {
+			dir := t.TempDir()
+			f, err := NewFile(FileOptions{Dir: dir, Compression: compression})
+			if err != nil {
+				t.Fatalf("NewFile: %v", err)
+			}
+
+			records := []*kgo.Record{
+				{Topic: "content", Key: []byte("content-1"), Value: []byte(`{"id":"content-1"}`)},
+				{Topic: "creator", Key: []byte("creator-1"), Value: []byte(`{"id":"creator-1"}`)},
+				{Topic: "content", Key: []byte("content-1")}, // Tombstones are not written
+				{Topic: "content", Key: []byte("content-2"), Value: []byte(`{"id":"content-2"}`)},
+			}
+			if err := f.Write(context.Background(), records); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if err := f.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			if _, lines := readNDJSON(t, dir, "content"); !slices.Equal(lines, []string{`{"id":"content-1"}`, `{"id":"content-2"}`}) {
+				t.Errorf("content lines = %q", lines)
+			}
+			if _, lines := readNDJSON(t, dir, "creator"); !slices.Equal(lines, []string{`{"id":"creator-1"}`}) {
+				t.Errorf("creator lines = %q", lines)
+			}
+		})
+	}
+}
+
+func TestFileRotates(t *testing.T) {
+	dir := t.TempDir()
+	value := []byte(`{"id":"content"}`) // 17 bytes with the newline
+	f, err := NewFile(FileOptions{Dir: dir, MaxBytes: 2 * int64(len(value)+1), Compression: CompressionGzip})
+	if err != nil {
+		t.Fatalf("NewFile: %v", err)
+	}
+	defer f.Close()
+
+	var want []string
+	for i := 0; i < 5; i++ {
+		if err := f.Write(context.Background(), []*kgo.Record{{Topic: "content", Value: value}}); err != nil {
+			t.Fatalf("Write %d: %v", i, err)
+		}
+		want = append(want, string(value))
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Two records fill a file, so five need three
+	files, lines := readNDJSON(t, dir, "content")
+	if files != 3 || !slices.Equal(lines, want) {
+		t.Errorf("rotated into %d files with lines %q, want 3 files with %d lines", files, lines, len(want))
+	}
+
+	// Age-based rotation is decided by the time of the write
+	aged, err := NewFile(FileOptions{Dir: t.TempDir(), MaxAge: time.Minute})
+	if err != nil {
+		t.Fatalf("NewFile: %v", err)
+	}
+	defer aged.Close()
+	now := time.Now()
+	first, err := aged.fileFor("content", now)
+	if err != nil {
+		t.Fatalf("fileFor: %v", err)
+	}
+	if same, _ := aged.fileFor("content", now.Add(59*time.Second)); same != first {
+		t.Error("file rotated before MaxAge")
+	}
+	if next, _ := aged.fileFor("content", now.Add(time.Minute)); next == first {
+		t.Error("file not rotated after MaxAge")
+	}
+}
+
+func TestNewFileRejectsBadOptions(t *testing.T) {
+	for _, opts := range []FileOptions{
+		{Compression: "lz4"},
+		{MaxBytes: -1},
+		{MaxAge: -time.Second},
+	} {
+		opts.Dir = t.TempDir()
+		if _, err := NewFile(opts); err == nil {
+			t.Errorf("NewFile(%+v) succeeded", opts)
+		}
+	}
+}
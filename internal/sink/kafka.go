@@ -0,0 +1,83 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// Kafka produces records to a Kafka-compatible cluster such as Redpanda
+type Kafka struct {
+	client *kgo.Client
+}
+
+// NewKafka connects to the cluster at brokers, a comma-separated list; extra
+// options such as a partitioner are applied after the defaults
+func NewKafka(ctx context.Context, brokers string, extra ...kgo.Opt) (*Kafka, error) {
+	// Create Redpanda client options
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(strings.Split(brokers, ",")...),
+		kgo.AllowAutoTopicCreation(),
+		kgo.ProducerBatchMaxBytes(1024 * 1024), // 1MB
+		kgo.ProducerLinger(5 * time.Millisecond),
+		kgo.RecordRetries(3),
+		kgo.RetryTimeout(10 * time.Second),
+	}
+	opts = append(opts, extra...)
+
+	// Create client
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Redpanda client: %w", err)
+	}
+
+	// Test connection
+	if err := checkConnection(ctx, client); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &Kafka{client: client}, nil
+}
+
+// checkConnection verifies the connection to Redpanda
+func checkConnection(ctx context.Context, client *kgo.Client) error {
+	// Attempt to list topics to check connection
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Request a list of topics to verify connection
+	req := kmsg.MetadataRequest{
+		Topics: []kmsg.MetadataRequestTopic{},
+	}
+
+	resp, err := client.Request(ctx, &req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Redpanda: %w", err)
+	}
+
+	metaResp := resp.(*kmsg.MetadataResponse)
+	if len(metaResp.Brokers) == 0 {
+		return fmt.Errorf("no brokers found in Redpanda cluster")
+	}
+
+	return nil
+}
+
+// Write produces records and waits for every one to be acknowledged
+func (k *Kafka) Write(ctx context.Context, records []*kgo.Record) error {
+	results := k.client.ProduceSync(ctx, records...)
+	return results.FirstErr()
+}
+
+// Close closes the Redpanda client
+func (k *Kafka) Close() error {
+	if k.client != nil {
+		k.client.Close()
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// MQTTMessage is one message published for a record
+type MQTTMessage struct {
+	Topic   string
+	Payload []byte
+	Retain  bool // Brokers keep the last retained message of a topic for new subscribers
+
+	// Published, if set, is called once the broker has acknowledged the
+	// message, from the goroutine that calls Messages
+	Published func()
+}
+
+// MQTTOptions configures an MQTT sink
+type MQTTOptions struct {
+	Broker         string // e.g. tcp://localhost:1883
+	ClientID       string
+	QoS            byte          // 0, 1 or 2
+	ConnectTimeout time.Duration // Default 10s
+	PublishTimeout time.Duration // Longest wait for a batch to be acknowledged; default 5s
+
+	// Messages returns what to publish for a record, if anything. It is called
+	// for one record at a time, in order, so it may keep state.
+	Messages func(record *kgo.Record) []MQTTMessage
+}
+
+// MQTTStats counts published messages
+type MQTTStats struct {
+	Published int64
+	Retained  int64
+}
+
+// MQTT publishes messages derived from records to an MQTT broker, the way
+// the platform notifies mobile clients. Each batch waits until the broker has
+// acknowledged all of its messages at the configured QoS.
+type MQTT struct {
+	opts   MQTTOptions
+	client mqtt.Client
+
+	mu    sync.Mutex
+	stats MQTTStats
+}
+
+// NewMQTT connects to the broker
+func NewMQTT(opts MQTTOptions) (*MQTT, error) {
+	if opts.QoS > 2 {
+		return nil, fmt.Errorf("QoS must be 0, 1 or 2")
+	}
+	if opts.Messages == nil {
+		return nil, fmt.Errorf("no message function configured")
+	}
+	if opts.ConnectTimeout <= 0 {
+		opts.ConnectTimeout = 10 * time.Second
+	}
+	if opts.PublishTimeout <= 0 {
+		opts.PublishTimeout = 5 * time.Second
+	}
+
+	clientOpts := mqtt.NewClientOptions().
+		AddBroker(opts.Broker).
+		SetClientID(opts.ClientID).
+		SetCleanSession(true).
+		SetAutoReconnect(true).
+		SetConnectTimeout(opts.ConnectTimeout)
+	client := mqtt.NewClient(clientOpts)
+
+	token := client.Connect()
+	if !token.WaitTimeout(opts.ConnectTimeout) {
+		client.Disconnect(0)
+		return nil, fmt.Errorf("timed out connecting to MQTT broker %s", opts.Broker)
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("failed to connect to MQTT broker %s: %w", opts.Broker, err)
+	}
+
+	return &MQTT{opts: opts, client: client}, nil
+}
+
+// Write publishes each record's messages and waits for them to be acknowledged
+func (m *MQTT) Write(ctx context.Context, records []*kgo.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var msgs []MQTTMessage
+	var tokens []mqtt.Token
+	for _, record := range records {
+		for _, msg := range m.opts.Messages(record) {
+			msgs = append(msgs, msg)
+			tokens = append(tokens, m.client.Publish(msg.Topic, m.opts.QoS, msg.Retain, msg.Payload))
+		}
+	}
+
+	deadline := time.NewTimer(m.opts.PublishTimeout)
+	defer deadline.Stop()
+
+	var errs []error
+	for i, token := range tokens {
+		select {
+		case <-token.Done():
+		case <-deadline.C:
+			return errors.Join(append(errs, fmt.Errorf("timed out waiting for MQTT acks"))...)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if err := token.Error(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to publish to %s: %w", msgs[i].Topic, err))
+			continue
+		}
+		m.stats.Published++
+		if msgs[i].Retain {
+			m.stats.Retained++
+		}
+		if msgs[i].Published != nil {
+			msgs[i].Published()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns publish counts so far
+func (m *MQTT) Stats() MQTTStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stats
+}
+
+// Close disconnects, giving in-flight messages a moment to finish
+func (m *MQTT) Close() error {
+	m.client.Disconnect(250)
+	return nil
+}
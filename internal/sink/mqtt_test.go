@@ -0,0 +1,188 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// fakeBroker is a minimal MQTT 3.1.1 broker that accepts one client and
+// records what it publishes. It answers QoS 1 publishes with PUBACK unless
+// acks are switched off.
+type fakeBroker struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []MQTTMessage
+	noAcks   bool
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	b := &fakeBroker{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) url() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+func (b *fakeBroker) setAcks(on bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.noAcks = !on
+}
+
+func (b *fakeBroker) received() []MQTTMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]MQTTMessage(nil), b.messages...)
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		header, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		length, err := readRemainingLength(r)
+		if err != nil {
+			return
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return
+		}
+
+		switch header >> 4 {
+		case 1: // CONNECT
+			conn.Write([]byte{0x20, 0x02, 0x00, 0x00})
+		case 3: // PUBLISH
+			qos := header >> 1 & 0x03
+			topicLen := int(binary.BigEndian.Uint16(body))
+			msg := MQTTMessage{Topic: string(body[2 : 2+topicLen]), Retain: header&0x01 == 1}
+			rest := body[2+topicLen:]
+			var id []byte
+			if qos > 0 {
+				id, rest = rest[:2], rest[2:]
+			}
+			msg.Payload = rest
+
+			b.mu.Lock()
+			b.messages = append(b.messages, msg)
+			ack := !b.noAcks
+			b.mu.Unlock()
+			if qos == 1 && ack {
+				conn.Write([]byte{0x40, 0x02, id[0], id[1]})
+			}
+		case 12: // PINGREQ
+			conn.Write([]byte{0xd0, 0x00})
+		case 14: // DISCONNECT
+			return
+		}
+	}
+}
+
+func readRemainingLength(r *bufio.Reader) (int, error) {
+	length, shift := 0, 0
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return length, nil
+		}
+		shift += 7
+	}
+}
+
+func TestMQTTPublishesMessages(t *testing.T) {
+	broker := newFakeBroker(t)
+
+	var published []string
+	m, err := NewMQTT(MQTTOptions{
+		Broker:         broker.url(),
+		ClientID:       "test",
+		QoS:            1,
+		PublishTimeout: 200 * time.Millisecond,
+		Messages: func(record *kgo.Record) []MQTTMessage {
+			if record.Value == nil {
+				return nil
+			}
+			key := string(record.Key)
+			return []MQTTMessage{{
+				Topic:     "platform/" + record.Topic + "/" + key,
+				Payload:   record.Value,
+				Retain:    record.Topic == "status",
+				Published: func() { published = append(published, key) },
+			}}
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewMQTT: %v", err)
+	}
+	defer m.Close()
+
+	ctx := context.Background()
+	records := []*kgo.Record{
+		{Topic: "status", Key: []byte("creator-1"), Value: []byte(`{"is_online":true}`)},
+		{Topic: "content", Key: []byte("creator-1"), Value: []byte(`{"content_id":"content-1"}`)},
+		{Topic: "content", Key: []byte("creator-2")}, // No messages
+	}
+	if err := m.Write(ctx, records); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got := broker.received()
+	if len(got) != 2 {
+		t.Fatalf("broker got %d messages, want 2", len(got))
+	}
+	if got[0].Topic != "platform/status/creator-1" || !got[0].Retain || string(got[0].Payload) != `{"is_online":true}` {
+		t.Errorf("message 0 = %+v", got[0])
+	}
+	if got[1].Topic != "platform/content/creator-1" || got[1].Retain {
+		t.Errorf("message 1 = %+v", got[1])
+	}
+	if stats := m.Stats(); stats.Published != 2 || stats.Retained != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if len(published) != 2 {
+		t.Errorf("Published called for %v, want both messages", published)
+	}
+
+	// Unacknowledged messages fail the batch and are not reported as published
+	broker.setAcks(false)
+	published = nil
+	if err := m.Write(ctx, records[:1]); err == nil {
+		t.Fatal("Write succeeded without an ack")
+	}
+	if len(published) != 0 {
+		t.Errorf("Published called for %v without an ack", published)
+	}
+}
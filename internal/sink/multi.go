@@ -0,0 +1,235 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// How Multi handles a sink's write errors
+const (
+	PolicyFailFast    = "fail-fast"        // Fail the batch and skip the sinks after this one
+	PolicyBestEffort  = "best-effort"      // Count the failure and drop the batch for this sink
+	PolicyBufferRetry = "buffer-and-retry" // Keep the batch and retry it before the sink's next batch
+)
+
+// DefaultRetryBuffer is the default number of records a buffer-and-retry
+// route holds
+const DefaultRetryBuffer = 10000
+
+// Route is one of the sinks Multi writes to
+type Route struct {
+	Name        string
+	Sink        Sink
+	Policy      string // Defaults to PolicyFailFast
+	RetryBuffer int    // Records held for retry under PolicyBufferRetry; the oldest are dropped beyond it
+}
+
+// RouteStats counts one route's writes
+type RouteStats struct {
+	Name      string
+	Policy    string
+	Batches   int64 // Batches written, including retried ones
+	Records   int64 // Records written, including retried ones
+	Failures  int64 // Failed writes
+	Retried   int64 // Records written from the retry buffer
+	Buffered  int   // Records waiting to be retried
+	Dropped   int64 // Records given up on after a best-effort failure or a full retry buffer
+	WriteTime time.Duration
+	LastError string
+}
+
+// Multi writes every batch to several sinks in turn, e.g. Kafka alongside an
+// NDJSON archive, so they all see identical input. Each sink gets its own
+// copies of the records and fails according to its route's policy. Sinks are
+// written in order, so fail-fast sinks listed first keep a failing batch away
+// from all the others.
+type Multi struct {
+	routes []*route
+}
+
+type route struct {
+	Route
+
+	mu      sync.Mutex
+	pending [][]*kgo.Record // Batches awaiting retry, oldest first
+	stats   RouteStats
+}
+
+// NewMulti returns a sink that writes to all of routes
+func NewMulti(routes ...Route) (*Multi, error) {
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("no sinks to write to")
+	}
+
+	m := &Multi{}
+	names := make(map[string]bool)
+	for _, r := range routes {
+		if names[r.Name] {
+			return nil, fmt.Errorf("sink %s listed more than once", r.Name)
+		}
+		names[r.Name] = true
+
+		switch r.Policy {
+		case "":
+			r.Policy = PolicyFailFast
+		case PolicyFailFast, PolicyBestEffort, PolicyBufferRetry:
+		default:
+			return nil, fmt.Errorf("unknown policy %q for sink %s (expected %s, %s or %s)",
+				r.Policy, r.Name, PolicyFailFast, PolicyBestEffort, PolicyBufferRetry)
+		}
+		if r.RetryBuffer <= 0 {
+			r.RetryBuffer = DefaultRetryBuffer
+		}
+
+		m.routes = append(m.routes, &route{Route: r, stats: RouteStats{Name: r.Name, Policy: r.Policy}})
+	}
+	return m, nil
+}
+
+// Sinks returns the sinks written to
+func (m *Multi) Sinks() []Sink {
+	sinks := make([]Sink, len(m.routes))
+	for i, r := range m.routes {
+		sinks[i] = r.Sink
+	}
+	return sinks
+}
+
+// Write writes records to each sink in order, stopping at the first fail-fast
+// sink that fails. Failures of other sinks are only counted.
+func (m *Multi) Write(ctx context.Context, records []*kgo.Record) error {
+	for _, r := range m.routes {
+		batch := records
+		if len(m.routes) > 1 {
+			batch = copyRecords(records)
+		}
+		if err := r.write(ctx, batch); err != nil {
+			return fmt.Errorf("%s sink: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// copyRecords returns shallow copies of records, since sinks such as Kafka
+// set fields on the records they write
+func copyRecords(records []*kgo.Record) []*kgo.Record {
+	copies := make([]*kgo.Record, len(records))
+	for i, record := range records {
+		copied := *record
+		copies[i] = &copied
+	}
+	return copies
+}
+
+// write writes one batch under the route's policy, returning only errors
+// that should fail the whole batch
+func (r *route) write(ctx context.Context, batch []*kgo.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Policy == PolicyBufferRetry {
+		// Keep batches in order: nothing new goes out before the backlog
+		if err := r.retryPending(ctx); err != nil {
+			r.buffer(batch)
+			return nil
+		}
+	}
+
+	err := r.send(ctx, batch, false)
+	if err == nil {
+		return nil
+	}
+
+	switch r.Policy {
+	case PolicyBestEffort:
+		r.stats.Dropped += int64(len(batch))
+		return nil
+	case PolicyBufferRetry:
+		r.buffer(batch)
+		return nil
+	}
+	return err
+}
+
+// send writes a batch to the sink and counts the outcome; r.mu must be held
+func (r *route) send(ctx context.Context, batch []*kgo.Record, retry bool) error {
+	start := time.Now()
+	err := r.Sink.Write(ctx, batch)
+	r.stats.WriteTime += time.Since(start)
+
+	if err != nil {
+		r.stats.Failures++
+		r.stats.LastError = err.Error()
+		return err
+	}
+
+	r.stats.Batches++
+	r.stats.Records += int64(len(batch))
+	if retry {
+		r.stats.Retried += int64(len(batch))
+	}
+	return nil
+}
+
+// retryPending writes buffered batches, oldest first, until one fails; r.mu
+// must be held
+func (r *route) retryPending(ctx context.Context) error {
+	for len(r.pending) > 0 {
+		batch := r.pending[0]
+		if err := r.send(ctx, batch, true); err != nil {
+			return err
+		}
+		r.pending = r.pending[1:]
+		r.stats.Buffered -= len(batch)
+	}
+	r.pending = nil
+	return nil
+}
+
+// buffer queues a batch for retry, dropping the oldest batches if the buffer
+// overflows; r.mu must be held
+func (r *route) buffer(batch []*kgo.Record) {
+	r.pending = append(r.pending, batch)
+	r.stats.Buffered += len(batch)
+
+	for r.stats.Buffered > r.RetryBuffer && len(r.pending) > 0 {
+		dropped := r.pending[0]
+		r.pending = r.pending[1:]
+		r.stats.Buffered -= len(dropped)
+		r.stats.Dropped += int64(len(dropped))
+	}
+}
+
+// Stats returns every route's counts so far
+func (m *Multi) Stats() []RouteStats {
+	stats := make([]RouteStats, len(m.routes))
+	for i, r := range m.routes {
+		r.mu.Lock()
+		stats[i] = r.stats
+		r.mu.Unlock()
+	}
+	return stats
+}
+
+// Close makes a last attempt at writing buffered batches, then closes every
+// sink
+func (m *Multi) Close() error {
+	var errs []error
+	for _, r := range m.routes {
+		r.mu.Lock()
+		if err := r.retryPending(context.Background()); err != nil {
+			errs = append(errs, fmt.Errorf("%s sink: %d buffered records not written: %w", r.Name, r.stats.Buffered, err))
+		}
+		r.mu.Unlock()
+
+		if err := r.Sink.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s sink: %w", r.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
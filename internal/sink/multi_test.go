@@ -0,0 +1,219 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// fakeSink records the keys of every batch it accepts and fails while failing
+// is set
+type fakeSink struct {
+	failing  bool
+	closeErr error
+	batches  [][]string
+	closed   bool
+}
+
+func (f *fakeSink) Write(ctx context.Context, records []*kgo.Record) error {
+	if f.failing {
+		return errors.New("sink unavailable")
+	}
+	keys := make([]string, len(records))
+	for i, record := range records {
+		keys[i] = string(record.Key)
+		record.Partition = 7 // Sinks may set fields on the records they write
+	}
+	f.batches = append(f.batches, keys)
+	return nil
+}
+
+func (f *fakeSink) Close() error {
+	f.closed = true
+	return f.closeErr
+}
+
+// keyedBatch returns a batch with one record per key
+func keyedBatch(keys ...string) []*kgo.Record {
+	records := make([]*kgo.Record, len(keys))
+	for i, key := range keys {
+		records[i] = &kgo.Record{Topic: "content", Key: []byte(key), Value: []byte(`{}`)}
+	}
+	return records
+}
+
+func TestMultiPolicies(t *testing.T) {
+	// Each write sends the next batch with the sink failing or not
+	type write struct {
+		keys    []string
+		failing bool
+	}
+	tests := []struct {
+		name        string
+		policy      string
+		retryBuffer int
+		writes      []write
+		wantErrs    []bool
+		wantBatches [][]string
+		wantStats   RouteStats
+	}{
+		{
+			name:        "fail-fast",
+			policy:      PolicyFailFast,
+			writes:      []write{{keys: []string{"a"}}, {keys: []string{"b"}, failing: true}, {keys: []string{"c"}}},
+			wantErrs:    []bool{false, true, false},
+			wantBatches: [][]string{{"a"}, {"c"}},
+			wantStats:   RouteStats{Batches: 2, Records: 2, Failures: 1},
+		},
+		{
+			name:        "best-effort drops failed batches",
+			policy:      PolicyBestEffort,
+			writes:      []write{{keys: []string{"a", "b"}, failing: true}, {keys: []string{"c"}}},
+			wantErrs:    []bool{false, false},
+			wantBatches: [][]string{{"c"}},
+			wantStats:   RouteStats{Batches: 1, Records: 1, Failures: 1, Dropped: 2},
+		},
+		{
+			name:   "buffer-and-retry sends the backlog first",
+			policy: PolicyBufferRetry,
+			writes: []write{
+				{keys: []string{"a"}, failing: true},
+				{keys: []string{"b"}, failing: true},
+				{keys: []string{"c"}},
+				{keys: []string{"d"}},
+			},
+			wantErrs:    []bool{false, false, false, false},
+			wantBatches: [][]string{{"a"}, {"b"}, {"c"}, {"d"}},
+			// The second write fails retrying "a" before "b" is tried
+			wantStats: RouteStats{Batches: 4, Records: 4, Failures: 2, Retried: 2},
+		},
+		{
+			name:        "buffer-and-retry drops the oldest on overflow",
+			policy:      PolicyBufferRetry,
+			retryBuffer: 3,
+			writes: []write{
+				{keys: []string{"a", "b"}, failing: true},
+				{keys: []string{"c"}, failing: true},
+				{keys: []string{"d", "e"}, failing: true},
+				{keys: []string{"f"}},
+			},
+			wantErrs:    []bool{false, false, false, false},
+			wantBatches: [][]string{{"c"}, {"d", "e"}, {"f"}},
+			wantStats:   RouteStats{Batches: 3, Records: 4, Failures: 3, Retried: 3, Dropped: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeSink{}
+			m, err := NewMulti(Route{Name: "fake", Sink: fake, Policy: test.policy, RetryBuffer: test.retryBuffer})
+			if err != nil {
+				t.Fatalf("NewMulti: %v", err)
+			}
+
+			for i, w := range test.writes {
+				fake.failing = w.failing
+				err := m.Write(context.Background(), keyedBatch(w.keys...))
+				if (err != nil) != test.wantErrs[i] {
+					t.Errorf("write %d error = %v, want error %t", i, err, test.wantErrs[i])
+				}
+			}
+
+			if !reflect.DeepEqual(fake.batches, test.wantBatches) {
+				t.Errorf("batches = %v, want %v", fake.batches, test.wantBatches)
+			}
+			stats := m.Stats()[0]
+			stats.WriteTime, stats.LastError = 0, ""
+			test.wantStats.Name, test.wantStats.Policy = "fake", test.policy
+			if stats != test.wantStats {
+				t.Errorf("stats = %+v, want %+v", stats, test.wantStats)
+			}
+		})
+	}
+}
+
+func TestMultiFailFastStopsLaterSinks(t *testing.T) {
+	archive, kafka, mirror := &fakeSink{}, &fakeSink{failing: true}, &fakeSink{}
+	m, err := NewMulti(
+		Route{Name: "archive", Sink: archive, Policy: PolicyBestEffort},
+		Route{Name: "kafka", Sink: kafka},
+		Route{Name: "mirror", Sink: mirror, Policy: PolicyBestEffort},
+	)
+	if err != nil {
+		t.Fatalf("NewMulti: %v", err)
+	}
+
+	records := keyedBatch("a")
+	err = m.Write(context.Background(), records)
+	if err == nil || !strings.HasPrefix(err.Error(), "kafka sink:") {
+		t.Errorf("Write error = %v, want the kafka sink's", err)
+	}
+	if len(archive.batches) != 1 || len(mirror.batches) != 0 {
+		t.Errorf("archive got %v and mirror %v, want only the archive written", archive.batches, mirror.batches)
+	}
+
+	// Every sink gets its own copies of the records
+	if records[0].Partition != 0 {
+		t.Error("a sink changed the caller's record")
+	}
+
+	stats := m.Stats()
+	if len(stats) != 3 || stats[0].Name != "archive" || stats[1].Failures != 1 || stats[1].LastError != "sink unavailable" {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestMultiCloseRetriesBuffer(t *testing.T) {
+	recovering, down := &fakeSink{failing: true}, &fakeSink{failing: true, closeErr: errors.New("close failed")}
+	m, err := NewMulti(
+		Route{Name: "recovering", Sink: recovering, Policy: PolicyBufferRetry},
+		Route{Name: "down", Sink: down, Policy: PolicyBufferRetry},
+	)
+	if err != nil {
+		t.Fatalf("NewMulti: %v", err)
+	}
+	if err := m.Write(context.Background(), keyedBatch("a", "b")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	recovering.failing = false
+	err = m.Close()
+	if !reflect.DeepEqual(recovering.batches, [][]string{{"a", "b"}}) {
+		t.Errorf("recovering sink got %v, want the buffered batch", recovering.batches)
+	}
+	if err == nil || !strings.Contains(err.Error(), "down sink: 2 buffered records not written") ||
+		!strings.Contains(err.Error(), "close failed") || strings.Contains(err.Error(), "recovering") {
+		t.Errorf("Close error = %v", err)
+	}
+	if !recovering.closed || !down.closed {
+		t.Error("sinks not closed")
+	}
+	if stats := m.Stats(); stats[0].Buffered != 0 || stats[0].Retried != 2 || stats[1].Buffered != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestNewMultiRejectsBadRoutes(t *testing.T) {
+	tests := [][]Route{
+		nil,
+		{{Name: "a", Sink: &fakeSink{}}, {Name: "a", Sink: &fakeSink{}}},
+		{{Name: "a", Sink: &fakeSink{}, Policy: "retry-forever"}},
+	}
+	for _, routes := range tests {
+		if _, err := NewMulti(routes...); err == nil {
+			t.Errorf("NewMulti(%+v) succeeded", routes)
+		}
+	}
+
+	m, err := NewMulti(Route{Name: "a", Sink: &fakeSink{}})
+	if err != nil {
+		t.Fatalf("NewMulti: %v", err)
+	}
+	if stats := m.Stats()[0]; stats.Policy != PolicyFailFast {
+		t.Errorf("default policy = %s, want %s", stats.Policy, PolicyFailFast)
+	}
+}
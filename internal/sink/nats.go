@@ -0,0 +1,178 @@
+package sink
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// NATSKeyHeader carries the record key on NATS messages
+const NATSKeyHeader = "Event-Key"
+
+// NATSOptions configures a NATS sink
+type NATSOptions struct {
+	URL          string
+	Stream       string
+	CreateStream bool          // Create the stream, or update its subjects, instead of requiring it to exist
+	Subjects     []string      // Subjects the stream captures, e.g. "platform.>"; only used when creating it
+	Duplicates   time.Duration // Window in which the stream drops messages with a repeated Nats-Msg-Id; only used when creating it
+	AckTimeout   time.Duration // Longest wait for a publish ack
+
+	// Subject returns the subject for a record; false skips the record
+	Subject func(record *kgo.Record) (string, bool)
+}
+
+// NATSStats counts published messages
+type NATSStats struct {
+	Published  int64
+	Duplicates int64 // Acked by the stream as duplicates and not stored again
+}
+
+// NATS publishes records to a JetStream stream and waits for every publish
+// to be acked. Each message carries a Nats-Msg-Id, the envelope's event ID
+// when records are wrapped and a hash of the record otherwise, so the stream
+// drops messages it has already stored within its duplicate window.
+type NATS struct {
+	opts NATSOptions
+	conn *nats.Conn
+	js   jetstream.JetStream
+
+	mu    sync.Mutex
+	stats NATSStats
+}
+
+// NewNATS connects to the server and checks, or creates, the stream
+func NewNATS(ctx context.Context, opts NATSOptions) (*NATS, error) {
+	if opts.Stream == "" {
+		return nil, fmt.Errorf("stream name cannot be empty")
+	}
+	if opts.Subject == nil {
+		return nil, fmt.Errorf("no subject function configured")
+	}
+	if opts.AckTimeout <= 0 {
+		opts.AckTimeout = 5 * time.Second
+	}
+
+	conn, err := nats.Connect(opts.URL, nats.Name("onlyfans-event-publisher"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if opts.CreateStream {
+		_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:       opts.Stream,
+			Subjects:   opts.Subjects,
+			Duplicates: opts.Duplicates,
+		})
+	} else {
+		_, err = js.Stream(ctx, opts.Stream)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set up stream %s: %w", opts.Stream, err)
+	}
+
+	return &NATS{opts: opts, conn: conn, js: js}, nil
+}
+
+// Write publishes records asynchronously and waits for all of their acks
+func (n *NATS) Write(ctx context.Context, records []*kgo.Record) error {
+	futures := make([]jetstream.PubAckFuture, 0, len(records))
+	for _, record := range records {
+		subject, ok := n.opts.Subject(record)
+		if !ok {
+			continue
+		}
+
+		msg := nats.NewMsg(subject)
+		msg.Data = record.Value
+		for _, header := range record.Headers {
+			msg.Header.Add(header.Key, string(header.Value))
+		}
+		msg.Header.Set(NATSKeyHeader, string(record.Key))
+		msg.Header.Set(jetstream.MsgIDHeader, natsMsgID(record))
+
+		future, err := n.js.PublishMsgAsync(msg, jetstream.WithExpectStream(n.opts.Stream))
+		if err != nil {
+			return fmt.Errorf("failed to publish to %s: %w", subject, err)
+		}
+		futures = append(futures, future)
+	}
+
+	timeout := time.NewTimer(n.opts.AckTimeout)
+	defer timeout.Stop()
+
+	var published, duplicates int64
+	var errs []error
+	for _, future := range futures {
+		select {
+		case ack := <-future.Ok():
+			published++
+			if ack.Duplicate {
+				duplicates++
+			}
+		case err := <-future.Err():
+			errs = append(errs, fmt.Errorf("publish to %s failed: %w", future.Msg().Subject, err))
+		case <-timeout.C:
+			return errors.Join(append(errs, fmt.Errorf("timed out waiting for publish acks"))...)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	n.mu.Lock()
+	n.stats.Published += published
+	n.stats.Duplicates += duplicates
+	n.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// natsMsgID returns the envelope's event ID, or else a hash of the record
+func natsMsgID(record *kgo.Record) string {
+	var envelope struct {
+		EventID string `json:"event_id"`
+	}
+	if json.Unmarshal(record.Value, &envelope) == nil && envelope.EventID != "" {
+		return envelope.EventID
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(record.Topic))
+	hash.Write([]byte{0})
+	hash.Write(record.Key)
+	hash.Write([]byte{0})
+	hash.Write(record.Value)
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// Stats returns publish counts so far
+func (n *NATS) Stats() NATSStats {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.stats
+}
+
+// Close closes the connection; Write has already waited for every ack
+func (n *NATS) Close() error {
+	n.conn.Close()
+	return nil
+}
@@ -0,0 +1,92 @@
+package sink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natstest "github.com/nats-io/nats-server/v2/test"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// runJetStream starts an embedded NATS server with JetStream enabled
+func runJetStream(t *testing.T) *server.Server {
+	t.Helper()
+	opts := natstest.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+
+	srv := natstest.RunServer(&opts)
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func TestNATSDeduplicatesRepublishedRecords(t *testing.T) {
+	srv := runJetStream(t)
+	ctx := context.Background()
+
+	n, err := NewNATS(ctx, NATSOptions{
+		URL:          srv.ClientURL(),
+		Stream:       "PLATFORM",
+		CreateStream: true,
+		Subjects:     []string{"platform.>"},
+		Duplicates:   time.Minute,
+		Subject: func(record *kgo.Record) (string, bool) {
+			return "platform." + record.Topic, record.Value != nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewNATS: %v", err)
+	}
+	defer n.Close()
+
+	raw := &kgo.Record{Topic: "content", Key: []byte("content-1"), Value: []byte(`{"id":"content-1"}`)}
+	wrapped := &kgo.Record{Topic: "content", Key: []byte("content-2"), Value: []byte(`{"event_id":"01J0000000000000000000000A","sequence":1}`)}
+	if err := n.Write(ctx, []*kgo.Record{raw, wrapped}); err != nil {
+		t.Fatalf("first Write: %v", err)
+	}
+
+	// The same raw record hashes to the same ID, and an envelope is
+	// deduplicated by its event ID even if the rest of it differs
+	rewrapped := &kgo.Record{Topic: "content", Key: []byte("content-2"), Value: []byte(`{"event_id":"01J0000000000000000000000A","sequence":2}`)}
+	other := &kgo.Record{Topic: "content", Key: []byte("content-3"), Value: []byte(`{"id":"content-3"}`)}
+	tombstone := &kgo.Record{Topic: "content", Key: []byte("content-1")}
+	if err := n.Write(ctx, []*kgo.Record{raw, rewrapped, other, tombstone}); err != nil {
+		t.Fatalf("second Write: %v", err)
+	}
+
+	if stats := n.Stats(); stats.Published != 5 || stats.Duplicates != 2 {
+		t.Errorf("stats = %+v, want 5 published with 2 duplicates", stats)
+	}
+
+	stream, err := n.js.Stream(ctx, "PLATFORM")
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.State.Msgs != 3 {
+		t.Errorf("stream holds %d messages, want 3", info.State.Msgs)
+	}
+
+	msg, err := stream.GetMsg(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetMsg: %v", err)
+	}
+	if got := msg.Header.Get(NATSKeyHeader); got != "content-1" {
+		t.Errorf("key header = %q, want content-1", got)
+	}
+	if got := msg.Header.Get("Nats-Msg-Id"); got != natsMsgID(raw) || len(got) != 32 {
+		t.Errorf("message ID = %q, want the record hash", got)
+	}
+	if msg, err = stream.GetMsg(ctx, 2); err != nil {
+		t.Fatalf("GetMsg: %v", err)
+	}
+	if got := msg.Header.Get("Nats-Msg-Id"); got != "01J0000000000000000000000A" {
+		t.Errorf("message ID = %q, want the envelope's event ID", got)
+	}
+}
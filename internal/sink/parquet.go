@@ -0,0 +1,289 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// CompressionSnappy is the default Parquet compression
+const CompressionSnappy = "snappy"
+
+// inProgressSuffix marks Parquet files that have no footer yet
+const inProgressSuffix = ".inprogress"
+
+// ParquetOptions configures a Parquet sink
+type ParquetOptions struct {
+	Dir         string
+	Tables      map[string]TopicTable // By topic; records on other topics are dropped
+	MaxRows     int64                 // Rotate once a file holds this many rows; 0 disables
+	MaxAge      time.Duration         // Rotate files older than this; 0 disables
+	Compression string                // "none", "snappy", "gzip" or "zstd"
+}
+
+// Parquet writes events as Parquet files partitioned by event type and event
+// date, laid out as <dir>/event_type=<type>/date=<YYYY-MM-DD>/<file>.parquet
+// so DuckDB and Spark can read the tree with Hive partitioning. Records are
+// decoded back into their Go types, so columns follow the model structs'
+// JSON keys; envelopes are unwrapped and records that do not decode, such as
+// chaos-corrupted ones, are dropped. A file only becomes readable once it is
+// rotated or the sink is closed, and is named *.parquet.inprogress until then.
+type Parquet struct {
+	opts   ParquetOptions
+	codec  parquet.CompressionCodec
+	tables map[string]*parquetTable
+
+	mu    sync.Mutex
+	files map[partition]*parquetFile
+	seq   int // Distinguishes files opened within the same second
+}
+
+type parquetTable struct {
+	eventType string
+	goType    reflect.Type
+	schema    string
+}
+
+type partition struct {
+	eventType string
+	date      string
+}
+
+// parquetFile is the file currently being written for one partition
+type parquetFile struct {
+	file     *os.File
+	writer   *writer.JSONWriter
+	rows     int64
+	openedAt time.Time
+}
+
+// NewParquet derives a schema for every table and returns a Parquet sink
+func NewParquet(opts ParquetOptions) (*Parquet, error) {
+	var codec parquet.CompressionCodec
+	switch opts.Compression {
+	case "", CompressionSnappy:
+		codec = parquet.CompressionCodec_SNAPPY
+	case CompressionNone:
+		codec = parquet.CompressionCodec_UNCOMPRESSED
+	case CompressionGzip:
+		codec = parquet.CompressionCodec_GZIP
+	case CompressionZstd:
+		codec = parquet.CompressionCodec_ZSTD
+	default:
+		return nil, fmt.Errorf("unknown compression %q (expected %s, %s, %s or %s)",
+			opts.Compression, CompressionNone, CompressionSnappy, CompressionGzip, CompressionZstd)
+	}
+	if opts.MaxRows < 0 || opts.MaxAge < 0 {
+		return nil, fmt.Errorf("rotation limits cannot be negative")
+	}
+
+	tables := make(map[string]*parquetTable, len(opts.Tables))
+	for topic, table := range opts.Tables {
+		goType := reflect.TypeOf(table.Event)
+		schema, err := parquetSchema(goType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive %s schema: %w", table.EventType, err)
+		}
+		tables[topic] = &parquetTable{eventType: table.EventType, goType: goType, schema: schema}
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return &Parquet{
+		opts:   opts,
+		codec:  codec,
+		tables: tables,
+		files:  make(map[partition]*parquetFile),
+	}, nil
+}
+
+// Write decodes each record and appends it to its partition's file, after
+// finishing files that are due or that belong to an earlier day
+func (p *Parquet) Write(ctx context.Context, records []*kgo.Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if err := p.rotate(now); err != nil {
+		return err
+	}
+	for _, record := range records {
+		table, ok := p.tables[record.Topic]
+		if !ok || record.Value == nil {
+			continue
+		}
+
+		event, ok := decodeEvent(record.Value, table.goType)
+		if !ok {
+			continue
+		}
+		row, err := parquetRow(event)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s event: %w", table.eventType, err)
+		}
+		data, err := json.Marshal(row)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s event: %w", table.eventType, err)
+		}
+
+		at := now
+		if timed, ok := event.Interface().(interface{ EventTime() time.Time }); ok && !timed.EventTime().IsZero() {
+			at = timed.EventTime()
+		}
+		part := partition{eventType: table.eventType, date: at.UTC().Format("2006-01-02")}
+
+		pf, err := p.fileFor(part, table, now)
+		if err != nil {
+			return err
+		}
+		if err := pf.writer.Write(string(data)); err != nil {
+			return fmt.Errorf("failed to write %s event: %w", table.eventType, err)
+		}
+		pf.rows++
+	}
+	return nil
+}
+
+// decodeEvent decodes a record value, unwrapping it first if it is an
+// envelope, into a new value of goType
+func decodeEvent(value []byte, goType reflect.Type) (reflect.Value, bool) {
+	var envelope struct {
+		EventID string          `json:"event_id"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(value, &envelope); err != nil {
+		return reflect.Value{}, false
+	}
+	if envelope.EventID != "" && len(envelope.Payload) > 0 {
+		value = envelope.Payload
+	}
+
+	event := reflect.New(goType)
+	if err := json.Unmarshal(value, event.Interface()); err != nil {
+		return reflect.Value{}, false
+	}
+	return event.Elem(), true
+}
+
+// fileFor returns the open file for a partition, rotating it first if it is
+// full or too old; p.mu must be held
+func (p *Parquet) fileFor(part partition, table *parquetTable, now time.Time) (*parquetFile, error) {
+	pf, ok := p.files[part]
+	if ok && !p.due(pf, now) {
+		return pf, nil
+	}
+
+	if ok {
+		if err := pf.close(); err != nil {
+			return nil, err
+		}
+		delete(p.files, part)
+	}
+
+	pf, err := p.open(part, table, now)
+	if err != nil {
+		return nil, err
+	}
+	p.files[part] = pf
+	return pf, nil
+}
+
+// rotate finishes every file that is due, or whose date is before today, so
+// partitions that stop receiving events are not left in progress; p.mu must
+// be held
+func (p *Parquet) rotate(now time.Time) error {
+	today := now.UTC().Format("2006-01-02")
+	var firstErr error
+	for part, pf := range p.files {
+		if part.date >= today && !p.due(pf, now) {
+			continue
+		}
+		if err := pf.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(p.files, part)
+	}
+	return firstErr
+}
+
+// due reports whether pf should be rotated before the next write
+func (p *Parquet) due(pf *parquetFile, now time.Time) bool {
+	if p.opts.MaxRows > 0 && pf.rows >= p.opts.MaxRows {
+		return true
+	}
+	return p.opts.MaxAge > 0 && now.Sub(pf.openedAt) >= p.opts.MaxAge
+}
+
+// open creates a new file in a partition's directory
+func (p *Parquet) open(part partition, table *parquetTable, now time.Time) (*parquetFile, error) {
+	dir := filepath.Join(p.opts.Dir, "event_type="+part.eventType, "date="+part.date)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create partition directory: %w", err)
+	}
+
+	p.seq++
+	name := fmt.Sprintf("part-%s-%04d.parquet", now.UTC().Format("20060102T150405Z"), p.seq)
+	file, err := os.OpenFile(filepath.Join(dir, name+inProgressSuffix), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	w, err := writer.NewJSONWriterFromWriter(table.schema, file, 1)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to create %s Parquet writer: %w", part.eventType, err)
+	}
+	w.CompressionType = p.codec
+	w.RowGroupSize = 16 * 1024 * 1024
+
+	return &parquetFile{file: file, writer: w, openedAt: now}, nil
+}
+
+// close writes the footer, syncs and closes the file and drops its
+// in-progress suffix
+func (pf *parquetFile) close() error {
+	name := pf.file.Name()
+	if err := pf.writer.WriteStop(); err != nil {
+		pf.file.Close()
+		return fmt.Errorf("failed to finish %s: %w", name, err)
+	}
+	if err := pf.file.Sync(); err != nil {
+		pf.file.Close()
+		return fmt.Errorf("failed to sync %s: %w", name, err)
+	}
+	if err := pf.file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", name, err)
+	}
+	if err := os.Rename(name, strings.TrimSuffix(name, inProgressSuffix)); err != nil {
+		return fmt.Errorf("failed to publish %s: %w", name, err)
+	}
+	return nil
+}
+
+// Close finishes every open file
+func (p *Parquet) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var firstErr error
+	for part, pf := range p.files {
+		if err := pf.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(p.files, part)
+	}
+	return firstErr
+}
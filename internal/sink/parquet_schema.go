@@ -0,0 +1,209 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaNode is a node of a parquet-go JSON schema
+type schemaNode struct {
+	Tag    string        `json:"Tag"`
+	Fields []*schemaNode `json:"Fields,omitempty"`
+}
+
+// parquetSchema derives a Parquet schema from an event type. Columns are
+// named after the JSON keys, times become UTC microsecond timestamps, slices
+// become lists, maps become maps and interface fields hold their JSON as a
+// string.
+func parquetSchema(t reflect.Type) (string, error) {
+	if t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("%s is not a struct", t)
+	}
+
+	fields, err := structSchema(t)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(&schemaNode{Tag: "name=parquet_go_root, repetitiontype=REQUIRED", Fields: fields})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s schema: %w", t, err)
+	}
+	return string(data), nil
+}
+
+func structSchema(t reflect.Type) ([]*schemaNode, error) {
+	var fields []*schemaNode
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := columnName(field)
+		if !ok {
+			continue
+		}
+
+		node, err := typeSchema(name, field.Type, "REQUIRED")
+		if err != nil {
+			return nil, fmt.Errorf("field %s.%s: %w", t.Name(), field.Name, err)
+		}
+		fields = append(fields, node)
+	}
+	return fields, nil
+}
+
+// typeSchema returns the schema of a value of type t; zero times, nil
+// pointers, slices and maps are written as nulls
+func typeSchema(name string, t reflect.Type, repetition string) (*schemaNode, error) {
+	tag := func(format string, args ...any) string {
+		return fmt.Sprintf("name=%s, "+format+", repetitiontype=%s", append([]any{name}, append(args, repetition)...)...)
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Interface:
+		return &schemaNode{Tag: tag("type=BYTE_ARRAY, convertedtype=UTF8")}, nil
+	case reflect.Bool:
+		return &schemaNode{Tag: tag("type=BOOLEAN")}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schemaNode{Tag: tag("type=INT64")}, nil
+	case reflect.Float32, reflect.Float64:
+		return &schemaNode{Tag: tag("type=DOUBLE")}, nil
+
+	case reflect.Pointer:
+		return typeSchema(name, t.Elem(), "OPTIONAL")
+
+	case reflect.Struct:
+		if t == timeType {
+			return &schemaNode{Tag: fmt.Sprintf("name=%s, type=INT64, convertedtype=TIMESTAMP_MICROS, isadjustedtoutc=true, repetitiontype=OPTIONAL", name)}, nil
+		}
+		fields, err := structSchema(t)
+		if err != nil {
+			return nil, err
+		}
+		return &schemaNode{Tag: fmt.Sprintf("name=%s, repetitiontype=%s", name, repetition), Fields: fields}, nil
+
+	case reflect.Slice:
+		element, err := typeSchema("element", t.Elem(), "REQUIRED")
+		if err != nil {
+			return nil, err
+		}
+		return &schemaNode{Tag: fmt.Sprintf("name=%s, type=LIST, repetitiontype=OPTIONAL", name), Fields: []*schemaNode{element}}, nil
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		key, err := typeSchema("key", t.Key(), "REQUIRED")
+		if err != nil {
+			return nil, err
+		}
+		value, err := typeSchema("value", t.Elem(), "REQUIRED")
+		if err != nil {
+			return nil, err
+		}
+		return &schemaNode{Tag: fmt.Sprintf("name=%s, type=MAP, repetitiontype=OPTIONAL", name), Fields: []*schemaNode{key, value}}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// parquetRow converts an event into the JSON object parquet-go expects for
+// the schema parquetSchema derived from its type
+func parquetRow(v reflect.Value) (map[string]any, error) {
+	row := make(map[string]any, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		name, ok := columnName(v.Type().Field(i))
+		if !ok {
+			continue
+		}
+
+		value, err := parquetValue(v.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			row[name] = value
+		}
+	}
+	return row, nil
+}
+
+// parquetValue converts one value; nil means null
+func parquetValue(v reflect.Value) (any, error) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return parquetValue(v.Elem())
+
+	case reflect.Struct:
+		if v.Type() == timeType {
+			t := v.Interface().(time.Time)
+			if t.IsZero() {
+				return nil, nil
+			}
+			return t.UnixMicro(), nil
+		}
+		return parquetRow(v)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		list := make([]any, v.Len())
+		for i := range list {
+			element, err := parquetValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = element
+		}
+		return list, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		entries := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := parquetValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			entries[iter.Key().String()] = value
+		}
+		return entries, nil
+	}
+
+	return v.Interface(), nil
+}
+
+// columnName returns the JSON key of an exported field
+func columnName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	}
+	return name, true
+}
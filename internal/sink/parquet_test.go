@@ -0,0 +1,239 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"onlyfans-event-publisher/internal/model"
+)
+
+func TestParquetSchema(t *testing.T) {
+	type nested struct {
+		Amount int64 `json:"amount"`
+	}
+	type event struct {
+		ID       string            `json:"id"`
+		Count    int               `json:"count,omitempty"`
+		Ratio    float64           `json:"ratio"`
+		Active   bool              `json:"active"`
+		At       time.Time         `json:"at"`
+		Tags     []string          `json:"tags"`
+		Labels   map[string]string `json:"labels"`
+		Price    nested            `json:"price"`
+		Optional *nested           `json:"optional"`
+		Detail   any               `json:"detail"`
+		Untagged string
+		Skipped  string `json:"-"`
+		internal string
+	}
+
+	schema, err := parquetSchema(reflect.TypeOf(event{}))
+	if err != nil {
+		t.Fatalf("parquetSchema: %v", err)
+	}
+	var root schemaNode
+	if err := json.Unmarshal([]byte(schema), &root); err != nil {
+		t.Fatalf("unmarshal schema: %v", err)
+	}
+
+	want := []string{
+		"name=id, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED",
+		"name=count, type=INT64, repetitiontype=REQUIRED",
+		"name=ratio, type=DOUBLE, repetitiontype=REQUIRED",
+		"name=active, type=BOOLEAN, repetitiontype=REQUIRED",
+		"name=at, type=INT64, convertedtype=TIMESTAMP_MICROS, isadjustedtoutc=true, repetitiontype=OPTIONAL",
+		"name=tags, type=LIST, repetitiontype=OPTIONAL",
+		"name=labels, type=MAP, repetitiontype=OPTIONAL",
+		"name=price, repetitiontype=REQUIRED",
+		"name=optional, repetitiontype=OPTIONAL",
+		"name=detail, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED",
+		"name=Untagged, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED",
+	}
+	if len(root.Fields) != len(want) {
+		t.Fatalf("schema has %d columns, want %d: %s", len(root.Fields), len(want), schema)
+	}
+	for i, field := range root.Fields {
+		if field.Tag != want[i] {
+			t.Errorf("column %d = %q, want %q", i, field.Tag, want[i])
+		}
+	}
+	if tags := root.Fields[5].Fields; len(tags) != 1 || !strings.HasPrefix(tags[0].Tag, "name=element, type=BYTE_ARRAY") {
+		t.Errorf("tags element = %+v", tags)
+	}
+
+	if _, err := parquetSchema(reflect.TypeOf("")); err == nil {
+		t.Error("derived a schema for a string")
+	}
+	if _, err := parquetSchema(reflect.TypeOf(struct{ C chan int }{})); err == nil {
+		t.Error("derived a schema for a channel")
+	}
+}
+
+// readParquet returns the rows of every finished file under dir, keyed by
+// partition directory, as JSON objects with parquet-go's field names
+func readParquet(t *testing.T, dir string) map[string][]map[string]any {
+	t.Helper()
+	names, err := filepath.Glob(filepath.Join(dir, "event_type=*", "date=*", "*.parquet"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+
+	rows := make(map[string][]map[string]any)
+	for _, name := range names {
+		fr, err := local.NewLocalFileReader(name)
+		if err != nil {
+			t.Fatalf("open %s: %v", name, err)
+		}
+		pr, err := reader.NewParquetReader(fr, nil, 1)
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		objects, err := pr.ReadByNumber(int(pr.GetNumRows()))
+		if err != nil {
+			t.Fatalf("read rows of %s: %v", name, err)
+		}
+		pr.ReadStop()
+		fr.Close()
+
+		data, err := json.Marshal(objects)
+		if err != nil {
+			t.Fatalf("marshal rows: %v", err)
+		}
+		var decoded []map[string]any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("unmarshal rows: %v", err)
+		}
+		rel, _ := filepath.Rel(dir, filepath.Dir(name))
+		rows[filepath.ToSlash(rel)] = append(rows[filepath.ToSlash(rel)], decoded...)
+	}
+	return rows
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	p, err := NewParquet(ParquetOptions{
+		Dir:    dir,
+		Tables: map[string]TopicTable{"content": {EventType: model.EventContent, Event: model.Content{}}},
+	})
+	if err != nil {
+		t.Fatalf("NewParquet: %v", err)
+	}
+	defer p.Close()
+
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	content := model.Content{
+		ID: "content-1", CreatorID: "creator-1", Title: "Leg day", ContentType: "video",
+		Price: model.NewMoney(999, "USD"), IsLocked: true, ViewCount: 10, LikeCount: 2,
+		CreatedAt: created, UpdatedAt: created, Tags: []string{"fitness", model.TagViral},
+	}
+	value, err := json.Marshal(content)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	envelope, err := model.NewSequencer("test").Wrap(model.EventContent, "content-2", model.Content{ID: "content-2", UpdatedAt: created.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	wrapped, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+
+	records := []*kgo.Record{
+		{Topic: "content", Value: value},
+		{Topic: "content", Value: wrapped},
+		{Topic: "content", Value: []byte(`{"id":`)},  // Corrupt
+		{Topic: "content", Key: []byte("content-1")}, // Tombstone
+		{Topic: "creator", Value: []byte(`{"id":"creator-1"}`)},
+	}
+	if err := p.Write(context.Background(), records); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	inProgress, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*"+inProgressSuffix))
+	if len(inProgress) != 2 {
+		t.Errorf("%d files in progress, want 2", len(inProgress))
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	rows := readParquet(t, dir)
+	first := rows["event_type=content/date=2024-05-01"]
+	second := rows["event_type=content/date=2024-05-02"]
+	if len(rows) != 2 || len(first) != 1 || len(second) != 1 {
+		t.Fatalf("rows = %v, want one per date", rows)
+	}
+
+	row := first[0]
+	if row["Id"] != "content-1" || row["Is_locked"] != true || row["View_count"] != float64(10) {
+		t.Errorf("row = %v", row)
+	}
+	if row["Created_at"] != float64(created.UnixMicro()) {
+		t.Errorf("created_at = %v, want %d", row["Created_at"], created.UnixMicro())
+	}
+	if price, _ := row["Price_money"].(map[string]any); price["Amount_minor"] != float64(999) || price["Currency"] != "USD" {
+		t.Errorf("price_money = %v", row["Price_money"])
+	}
+	if tags, _ := json.Marshal(row["Tags"]); !strings.Contains(string(tags), `"fitness"`) || !strings.Contains(string(tags), `"viral"`) {
+		t.Errorf("tags = %s", tags)
+	}
+	if second[0]["Id"] != "content-2" {
+		t.Errorf("unwrapped row = %v", second[0])
+	}
+}
+
+func TestParquetFinishesStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	p, err := NewParquet(ParquetOptions{
+		Dir:    dir,
+		Tables: map[string]TopicTable{"content": {EventType: model.EventContent, Event: model.Content{}}},
+	})
+	if err != nil {
+		t.Fatalf("NewParquet: %v", err)
+	}
+	defer p.Close()
+
+	ctx := context.Background()
+	old, err := json.Marshal(model.Content{ID: "content-1", UpdatedAt: time.Now().AddDate(0, 0, -2)})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := p.Write(ctx, []*kgo.Record{{Topic: "content", Value: old}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	// A write for another partition finishes the earlier day's file
+	if err := p.Write(ctx, []*kgo.Record{{Topic: "creator", Value: []byte(`{}`)}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if inProgress, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*"+inProgressSuffix)); len(inProgress) != 0 {
+		t.Errorf("files still in progress: %v", inProgress)
+	}
+	if finished, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*.parquet")); len(finished) != 1 {
+		t.Errorf("finished files = %v, want 1", finished)
+	}
+}
+
+func TestNewParquetRejectsBadOptions(t *testing.T) {
+	for _, opts := range []ParquetOptions{
+		{Compression: "lz4"},
+		{MaxRows: -1},
+		{MaxAge: -time.Second},
+		{Tables: map[string]TopicTable{"x": {EventType: "x", Event: ""}}},
+	} {
+		opts.Dir = t.TempDir()
+		if _, err := NewParquet(opts); err == nil {
+			t.Errorf("NewParquet(%+v) succeeded", opts)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package sink
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Partitioning strategies
+const (
+	PartitionKey        = "key"         // Hash the record key the way Kafka does
+	PartitionCreatorID  = "creator_id"  // Hash the creator ID, so each creator's events share a partition
+	PartitionContentID  = "content_id"  // Hash the content ID, so each post's events share a partition
+	PartitionRoundRobin = "round_robin" // Spread records evenly, ignoring keys
+	PartitionSticky     = "sticky"      // Fill one partition's batch at a time, ignoring keys
+	PartitionCategory   = "category"    // Hash the creator's category, so each category shares a partition
+)
+
+// PartitionStrategies lists every partitioning strategy
+var PartitionStrategies = []string{
+	PartitionKey, PartitionCreatorID, PartitionContentID, PartitionRoundRobin, PartitionSticky, PartitionCategory,
+}
+
+// NewPartitioner returns a Kafka partitioner that applies a strategy per
+// topic, hashing keys for topics without one. field returns the value the
+// creator_id, content_id and category strategies hash for a record: its
+// creator ID, content ID or creator category. Records keep their keys, so
+// compacted topics still keep one record per event; records field returns
+// "" for are partitioned by key.
+func NewPartitioner(strategies map[string]string, field func(record *kgo.Record, strategy string) string) (kgo.Partitioner, error) {
+	keyed := kgo.StickyKeyPartitioner(nil)
+	p := &topicPartitioner{byTopic: make(map[string]kgo.Partitioner, len(strategies)), fallback: keyed}
+	for topic, strategy := range strategies {
+		switch strategy {
+		case PartitionKey:
+			p.byTopic[topic] = keyed
+		case PartitionRoundRobin:
+			p.byTopic[topic] = kgo.RoundRobinPartitioner()
+		case PartitionSticky:
+			p.byTopic[topic] = kgo.StickyPartitioner()
+		case PartitionCreatorID, PartitionContentID, PartitionCategory:
+			if field == nil {
+				return nil, fmt.Errorf("no %s lookup for %s", strategy, topic)
+			}
+			p.byTopic[topic] = fieldPartitioner(strategy, field, keyed)
+		default:
+			return nil, fmt.Errorf("unknown partitioning strategy %q for %s", strategy, topic)
+		}
+	}
+	return p, nil
+}
+
+// topicPartitioner picks a partitioner by topic
+type topicPartitioner struct {
+	byTopic  map[string]kgo.Partitioner
+	fallback kgo.Partitioner
+}
+
+func (p *topicPartitioner) ForTopic(topic string) kgo.TopicPartitioner {
+	if partitioner, ok := p.byTopic[topic]; ok {
+		return partitioner.ForTopic(topic)
+	}
+	return p.fallback.ForTopic(topic)
+}
+
+// fieldPartitioner sends every record with the same value of a field to the
+// same partition. Hashing categories makes a few partitions hot when they are
+// uneven.
+func fieldPartitioner(strategy string, field func(*kgo.Record, string) string, keyed kgo.Partitioner) kgo.Partitioner {
+	hash := kgo.KafkaHasher(func(b []byte) uint32 {
+		h := fnv.New32a()
+		h.Write(b)
+		return h.Sum32()
+	})
+
+	return kgo.BasicConsistentPartitioner(func(topic string) func(*kgo.Record, int) int {
+		byKey := keyed.ForTopic(topic)
+		return func(record *kgo.Record, n int) int {
+			if value := field(record, strategy); value != "" {
+				return hash([]byte(value), n)
+			}
+			return byKey.Partition(record, n)
+		}
+	})
+}
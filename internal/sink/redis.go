@@ -0,0 +1,134 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Stream entry fields
+const (
+	RedisKeyField     = "key"
+	RedisValueField   = "value" // Left out for tombstones
+	RedisHeaderPrefix = "header:"
+)
+
+// RedisOptions configures a Redis Streams sink
+type RedisOptions struct {
+	URL          string // e.g. redis://localhost:6379/0
+	StreamPrefix string // Prepended to the topic to name its stream
+	MaxLen       int64  // Trim streams to about this many entries; 0 disables
+	ExactMaxLen  bool   // Trim to exactly MaxLen, which is slower than letting Redis trim whole nodes
+}
+
+// RedisStats counts added stream entries
+type RedisStats struct {
+	Added int64
+}
+
+// Redis adds every record to a Redis stream named after its topic, with its
+// key, value and headers as entry fields. Each batch is sent as one pipeline,
+// so a mixed content and creator batch costs a single round trip.
+type Redis struct {
+	opts   RedisOptions
+	client *redis.Client
+
+	mu    sync.Mutex
+	stats RedisStats
+}
+
+// NewRedis connects to the server and checks that it responds
+func NewRedis(ctx context.Context, opts RedisOptions) (*Redis, error) {
+	if opts.MaxLen < 0 {
+		return nil, fmt.Errorf("max length cannot be negative")
+	}
+
+	redisOpts, err := redis.ParseURL(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(redisOpts)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return &Redis{opts: opts, client: client}, nil
+}
+
+// Write adds records to their streams in a single pipeline
+func (r *Redis) Write(ctx context.Context, records []*kgo.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(records))
+	for i, record := range records {
+		cmds[i] = pipe.XAdd(ctx, r.xaddArgs(record))
+	}
+
+	// Exec reports only the first failure, so collect them from the commands
+	pipe.Exec(ctx)
+
+	var added int64
+	var errs []error
+	for i, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to add to stream %s: %w", r.stream(records[i]), err))
+			continue
+		}
+		added++
+	}
+
+	r.mu.Lock()
+	r.stats.Added += added
+	r.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// xaddArgs builds the XADD for one record
+func (r *Redis) xaddArgs(record *kgo.Record) *redis.XAddArgs {
+	values := make([]any, 0, 4+2*len(record.Headers))
+	values = append(values, RedisKeyField, string(record.Key))
+	if record.Value != nil {
+		values = append(values, RedisValueField, string(record.Value))
+	}
+	for _, header := range record.Headers {
+		values = append(values, RedisHeaderPrefix+header.Key, string(header.Value))
+	}
+
+	return &redis.XAddArgs{
+		Stream: r.stream(record),
+		MaxLen: r.opts.MaxLen,
+		Approx: !r.opts.ExactMaxLen,
+		Values: values,
+	}
+}
+
+// stream returns the name of a record's stream
+func (r *Redis) stream(record *kgo.Record) string {
+	return r.opts.StreamPrefix + record.Topic
+}
+
+// Stats returns the number of entries added so far
+func (r *Redis) Stats() RedisStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stats
+}
+
+// Close closes the client
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,108 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func newTestRedis(t *testing.T, opts RedisOptions) (*Redis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	opts.URL = "redis://" + server.Addr() + "/0"
+
+	r, err := NewRedis(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("NewRedis: %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return r, client
+}
+
+func TestRedisAddsRecordsToStreams(t *testing.T) {
+	r, client := newTestRedis(t, RedisOptions{StreamPrefix: "platform:"})
+	ctx := context.Background()
+
+	records := []*kgo.Record{
+		{
+			Topic:   "content",
+			Key:     []byte("content-1"),
+			Value:   []byte(`{"id":"content-1"}`),
+			Headers: []kgo.RecordHeader{{Key: "event-time", Value: []byte("2024-01-01T00:00:00Z")}},
+		},
+		{Topic: "creator", Key: []byte("creator-1"), Value: []byte(`{"id":"creator-1"}`)},
+		{Topic: "content", Key: []byte("content-2"), Value: []byte(`{"id":"content-2"}`)},
+		{Topic: "content", Key: []byte("content-1")}, // Tombstone
+	}
+	if err := r.Write(ctx, records); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if stats := r.Stats(); stats.Added != int64(len(records)) {
+		t.Errorf("added %d entries, want %d", stats.Added, len(records))
+	}
+
+	content, err := client.XRange(ctx, "platform:content", "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRANGE content: %v", err)
+	}
+	if len(content) != 3 {
+		t.Fatalf("content stream holds %d entries, want 3", len(content))
+	}
+	first := content[0].Values
+	if first[RedisKeyField] != "content-1" || first[RedisValueField] != `{"id":"content-1"}` ||
+		first[RedisHeaderPrefix+"event-time"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("first entry = %v", first)
+	}
+	if _, ok := content[2].Values[RedisValueField]; ok || content[2].Values[RedisKeyField] != "content-1" {
+		t.Errorf("tombstone entry = %v, want a key and no value", content[2].Values)
+	}
+
+	creators, err := client.XLen(ctx, "platform:creator").Result()
+	if err != nil {
+		t.Fatalf("XLEN creator: %v", err)
+	}
+	if creators != 1 {
+		t.Errorf("creator stream holds %d entries, want 1", creators)
+	}
+}
+
+func TestRedisTrimsStreams(t *testing.T) {
+	r, client := newTestRedis(t, RedisOptions{MaxLen: 2, ExactMaxLen: true})
+	ctx := context.Background()
+
+	var records []*kgo.Record
+	for i := 1; i <= 5; i++ {
+		records = append(records, &kgo.Record{Topic: "content", Key: []byte(fmt.Sprintf("content-%d", i)), Value: []byte(`{}`)})
+	}
+	if err := r.Write(ctx, records); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	entries, err := client.XRange(ctx, "content", "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRANGE: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Values[RedisKeyField] != "content-4" || entries[1].Values[RedisKeyField] != "content-5" {
+		t.Errorf("entries after trimming = %v, want content-4 and content-5", entries)
+	}
+
+	// Trimming is approximate unless asked otherwise
+	if args := r.xaddArgs(records[0]); args.MaxLen != 2 || args.Approx {
+		t.Errorf("exact XADD args = %+v", args)
+	}
+	approx := &Redis{opts: RedisOptions{MaxLen: 1000}}
+	if args := approx.xaddArgs(records[0]); args.MaxLen != 1000 || !args.Approx {
+		t.Errorf("approximate XADD args = %+v", args)
+	}
+	unbounded := &Redis{}
+	if args := unbounded.xaddArgs(records[0]); args.MaxLen != 0 {
+		t.Errorf("untrimmed XADD args = %+v", args)
+	}
+}
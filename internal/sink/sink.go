@@ -0,0 +1,32 @@
+// Package sink delivers published records to a destination: Kafka, files or
+// anything else that accepts a stream of keyed events.
+package sink
+
+import (
+	"context"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Sink writes batches of records. Records use kgo.Record as a plain carrier of
+// topic, key, headers and value; sinks other than Kafka map those onto their
+// own concepts. A nil Value is a tombstone for Key.
+type Sink interface {
+	// Write delivers records in order, returning once they are durable or
+	// failed
+	Write(ctx context.Context, records []*kgo.Record) error
+
+	// Close flushes anything buffered and releases the sink's resources
+	Close() error
+}
+
+// ChaosHeader names the record header that marks deliberately corrupted
+// records
+const ChaosHeader = "chaos-corruption"
+
+// TopicTable describes the events published to one topic, for sinks that
+// name or decode records by event type
+type TopicTable struct {
+	EventType string // e.g. the Parquet partition name
+	Event     any    // A value of the Go type the topic's JSON decodes into
+}
@@ -0,0 +1,97 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Stdout output formats
+const (
+	FormatPretty  = "pretty"
+	FormatCompact = "compact"
+)
+
+// Stdout prints every record as a JSON object with its topic, key, headers
+// and value, one object per line in compact format. It needs no broker, which
+// makes it handy for inspecting what the simulator emits.
+type Stdout struct {
+	pretty bool
+
+	mu     sync.Mutex
+	writer *bufio.Writer
+}
+
+// printedRecord is how a record is printed; values that are not valid JSON,
+// such as chaos-corrupted ones, are printed as strings
+type printedRecord struct {
+	Topic   string            `json:"topic"`
+	Key     string            `json:"key"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Value   any               `json:"value"` // nil for tombstones
+}
+
+// NewStdout returns a sink that prints records to w in the given format
+func NewStdout(w io.Writer, format string) (*Stdout, error) {
+	switch format {
+	case FormatPretty, FormatCompact:
+	default:
+		return nil, fmt.Errorf("unknown format %q (expected %s or %s)", format, FormatPretty, FormatCompact)
+	}
+	return &Stdout{pretty: format == FormatPretty, writer: bufio.NewWriter(w)}, nil
+}
+
+// Write prints records in order
+func (s *Stdout) Write(ctx context.Context, records []*kgo.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range records {
+		printed := printedRecord{Topic: record.Topic, Key: string(record.Key)}
+		if len(record.Headers) > 0 {
+			printed.Headers = make(map[string]string, len(record.Headers))
+			for _, header := range record.Headers {
+				printed.Headers[header.Key] = string(header.Value)
+			}
+		}
+		switch {
+		case record.Value == nil:
+		case json.Valid(record.Value):
+			printed.Value = json.RawMessage(record.Value)
+		default:
+			printed.Value = string(record.Value)
+		}
+
+		var data []byte
+		var err error
+		if s.pretty {
+			data, err = json.MarshalIndent(printed, "", "  ")
+		} else {
+			data, err = json.Marshal(printed)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to format %s record: %w", record.Topic, err)
+		}
+
+		s.writer.Write(data)
+		s.writer.WriteByte('\n')
+	}
+
+	if err := s.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to print records: %w", err)
+	}
+	return nil
+}
+
+// Close flushes anything not yet printed
+func (s *Stdout) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.writer.Flush()
+}
@@ -0,0 +1,74 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestStdoutPrintsRecords(t *testing.T) {
+	records := []*kgo.Record{
+		{
+			Topic:   "content",
+			Key:     []byte("content-1"),
+			Value:   []byte(`{"id":"content-1"}`),
+			Headers: []kgo.RecordHeader{{Key: "event-time", Value: []byte("2024-01-01T00:00:00Z")}},
+		},
+		{Topic: "content", Key: []byte("content-1")},                          // Tombstone
+		{Topic: "content", Key: []byte("content-2"), Value: []byte(`{"id":`)}, // Corrupt
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatCompact, `{"topic":"content","key":"content-1","headers":{"event-time":"2024-01-01T00:00:00Z"},"value":{"id":"content-1"}}
+{"topic":"content","key":"content-1","value":null}
+{"topic":"content","key":"content-2","value":"{\"id\":"}
+`},
+		{FormatPretty, `{
+  "topic": "content",
+  "key": "content-1",
+  "headers": {
+    "event-time": "2024-01-01T00:00:00Z"
+  },
+  "value": {
+    "id": "content-1"
+  }
+}
+{
+  "topic": "content",
+  "key": "content-1",
+  "value": null
+}
+{
+  "topic": "content",
+  "key": "content-2",
+  "value": "{\"id\":"
+}
+`},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		s, err := NewStdout(&out, test.format)
+		if err != nil {
+			t.Fatalf("NewStdout(%s): %v", test.format, err)
+		}
+		if err := s.Write(context.Background(), records); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if got := out.String(); got != test.want {
+			t.Errorf("%s output:\n%s\nwant:\n%s", test.format, got, test.want)
+		}
+	}
+
+	if _, err := NewStdout(&strings.Builder{}, "yaml"); err == nil {
+		t.Error("NewStdout accepted an unknown format")
+	}
+}
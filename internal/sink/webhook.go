@@ -0,0 +1,351 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Webhook request headers
+const (
+	WebhookIDHeader        = "X-Webhook-Id" // Same for every attempt, so receivers can drop retries they already handled
+	WebhookTopicHeader     = "X-Webhook-Topic"
+	WebhookKeyHeader       = "X-Webhook-Key" // Single-event requests only
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookOptions configures a Webhook sink
+type WebhookOptions struct {
+	URLs           map[string]string // By topic; records on other topics are dropped
+	Secret         string            // HMAC-SHA256 signing key; empty sends unsigned requests
+	BatchSize      int               // Events per request; 1 posts each event as a JSON object, more as an array
+	Timeout        time.Duration     // Per attempt
+	MaxRetries     int               // Retries after the first attempt
+	Backoff        time.Duration     // Delay before the first retry, doubled for each one after
+	MaxBackoff     time.Duration     // Longest delay between retries
+	DeadLetterPath string            // NDJSON file for events that could not be delivered
+	Client         *http.Client      // Optional
+}
+
+// WebhookStats counts webhook deliveries
+type WebhookStats struct {
+	Requests     int64 // Successful requests
+	Delivered    int64 // Events in successful requests
+	Retries      int64
+	DeadLettered int64
+}
+
+// Webhook POSTs events to HTTP endpoints the way the platform notifies
+// partners. Requests are signed with
+//
+//	X-Webhook-Signature: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+//
+// and retried with exponential backoff on timeouts, connection errors and 5xx
+// responses. Events whose request fails permanently, or still fails after the
+// last retry, are appended to the dead-letter file. Each topic's events are
+// delivered in order; topics are delivered concurrently. Tombstones are not
+// sent.
+type Webhook struct {
+	opts   WebhookOptions
+	client *http.Client
+
+	mu         sync.Mutex
+	deadLetter *os.File
+	stats      WebhookStats
+}
+
+// deadLetter is one line of the dead-letter file
+type deadLetter struct {
+	URL      string          `json:"url"`
+	Topic    string          `json:"topic"`
+	Key      string          `json:"key"`
+	Status   int             `json:"status,omitempty"` // Last HTTP status, if any
+	Error    string          `json:"error"`
+	Attempts int             `json:"attempts"`
+	FailedAt time.Time       `json:"failed_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// NewWebhook validates opts and returns a Webhook sink
+func NewWebhook(opts WebhookOptions) (*Webhook, error) {
+	if len(opts.URLs) == 0 {
+		return nil, fmt.Errorf("no webhook URLs configured")
+	}
+	if opts.BatchSize < 1 {
+		return nil, fmt.Errorf("batch size must be at least 1")
+	}
+	if opts.MaxRetries < 0 || opts.Backoff < 0 || opts.MaxBackoff < 0 {
+		return nil, fmt.Errorf("retry settings cannot be negative")
+	}
+	if opts.DeadLetterPath == "" {
+		return nil, fmt.Errorf("dead-letter path cannot be empty")
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = 30 * time.Second
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Webhook{opts: opts, client: client}, nil
+}
+
+// Write delivers records, returning an error only if they could neither be
+// delivered nor dead-lettered
+func (w *Webhook) Write(ctx context.Context, records []*kgo.Record) error {
+	var topics []string
+	byTopic := make(map[string][]*kgo.Record)
+	for _, record := range records {
+		if _, ok := w.opts.URLs[record.Topic]; !ok || record.Value == nil {
+			continue
+		}
+		if _, ok := byTopic[record.Topic]; !ok {
+			topics = append(topics, record.Topic)
+		}
+		byTopic[record.Topic] = append(byTopic[record.Topic], record)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(topics))
+	for i, topic := range topics {
+		wg.Add(1)
+		go func(i int, topic string) {
+			defer wg.Done()
+			errs[i] = w.deliverTopic(ctx, topic, byTopic[topic])
+		}(i, topic)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// deliverTopic sends one topic's records in requests of up to BatchSize
+func (w *Webhook) deliverTopic(ctx context.Context, topic string, records []*kgo.Record) error {
+	url := w.opts.URLs[topic]
+	for _, batch := range webhookBatches(records, w.opts.BatchSize) {
+		status, attempts, err := w.post(ctx, url, topic, batch)
+		if err == nil {
+			w.mu.Lock()
+			w.stats.Requests++
+			w.stats.Delivered += int64(len(batch))
+			w.mu.Unlock()
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := w.deadLetterBatch(url, topic, batch, status, attempts, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// webhookBatches splits records, in order, into requests of up to size
+// records. Corrupt records go in requests of their own, so a receiver that
+// rejects them still accepts the valid records around them.
+func webhookBatches(records []*kgo.Record, size int) [][]*kgo.Record {
+	var batches [][]*kgo.Record
+	var batch []*kgo.Record
+	for _, record := range records {
+		if size > 1 && isCorrupt(record) {
+			if len(batch) > 0 {
+				batches = append(batches, batch)
+				batch = nil
+			}
+			batches = append(batches, []*kgo.Record{record})
+			continue
+		}
+		batch = append(batch, record)
+		if len(batch) == size {
+			batches = append(batches, batch)
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// isCorrupt reports whether a record is marked as corrupted by chaos or its
+// value is not valid JSON
+func isCorrupt(record *kgo.Record) bool {
+	for _, header := range record.Headers {
+		if header.Key == ChaosHeader {
+			return true
+		}
+	}
+	return !json.Valid(record.Value)
+}
+
+// post sends one request, retrying while it fails transiently. It returns the
+// last HTTP status (0 if there was none) and the number of attempts made.
+func (w *Webhook) post(ctx context.Context, url, topic string, batch []*kgo.Record) (int, int, error) {
+	// Values are sent as they are, so consumers see chaos payloads too
+	body := batch[0].Value
+	if w.opts.BatchSize > 1 {
+		body = []byte{'['}
+		for i, record := range batch {
+			if i > 0 {
+				body = append(body, ',')
+			}
+			body = append(body, record.Value...)
+		}
+		body = append(body, ']')
+	}
+
+	id := ulid.Make().String()
+	backoff := w.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		status, retryable, err := w.send(ctx, url, topic, id, batch, body)
+		if err == nil {
+			return status, attempt, nil
+		}
+		if !retryable || attempt > w.opts.MaxRetries {
+			return status, attempt, err
+		}
+
+		w.mu.Lock()
+		w.stats.Retries++
+		w.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return status, attempt, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, w.opts.MaxBackoff)
+	}
+}
+
+// send makes a single attempt, reporting whether a failure is worth retrying
+func (w *Webhook) send(ctx context.Context, url, topic, id string, batch []*kgo.Record, body []byte) (int, bool, error) {
+	if w.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.opts.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, id)
+	req.Header.Set(WebhookTopicHeader, topic)
+	if w.opts.BatchSize == 1 {
+		req.Header.Set(WebhookKeyHeader, string(batch[0].Key))
+	}
+	if w.opts.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(w.opts.Secret, time.Now(), body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// Timeouts and connection failures are transient unless we are
+		// shutting down
+		return 0, ctx.Err() == nil || errors.Is(ctx.Err(), context.DeadlineExceeded), fmt.Errorf("request failed: %w", err)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, false, nil
+	case resp.StatusCode >= 500:
+		return resp.StatusCode, true, fmt.Errorf("server error: %s", resp.Status)
+	default:
+		return resp.StatusCode, false, fmt.Errorf("rejected: %s", resp.Status)
+	}
+}
+
+// SignWebhook returns the signature header value for a request body sent at
+// the given time
+func SignWebhook(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deadLetterBatch appends each event of a failed request to the dead-letter file
+func (w *Webhook) deadLetterBatch(url, topic string, batch []*kgo.Record, status, attempts int, cause error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.deadLetter == nil {
+		file, err := os.OpenFile(w.opts.DeadLetterPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open dead-letter file: %w", err)
+		}
+		w.deadLetter = file
+	}
+
+	var lines []byte
+	now := time.Now()
+	for _, record := range batch {
+		value := json.RawMessage(record.Value)
+		if !json.Valid(value) {
+			value, _ = json.Marshal(string(record.Value))
+		}
+		line, err := json.Marshal(deadLetter{
+			URL:      url,
+			Topic:    topic,
+			Key:      string(record.Key),
+			Status:   status,
+			Error:    cause.Error(),
+			Attempts: attempts,
+			FailedAt: now,
+			Value:    value,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode dead letter: %w", err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+
+	if _, err := w.deadLetter.Write(lines); err != nil {
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+	w.stats.DeadLettered += int64(len(batch))
+	return nil
+}
+
+// Stats returns delivery counts so far
+func (w *Webhook) Stats() WebhookStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.stats
+}
+
+// Close closes the dead-letter file
+func (w *Webhook) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.deadLetter == nil {
+		return nil
+	}
+	err := w.deadLetter.Close()
+	w.deadLetter = nil
+	return err
+}
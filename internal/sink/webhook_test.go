@@ -0,0 +1,311 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// receiver records the requests a test server gets
+type receiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) record(req *http.Request) int {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	return len(r.requests)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func newTestWebhook(t *testing.T, url string, opts WebhookOptions) *Webhook {
+	t.Helper()
+	opts.URLs = map[string]string{"content": url}
+	opts.DeadLetterPath = filepath.Join(t.TempDir(), "dead-letters.ndjson")
+	if opts.BatchSize == 0 {
+		opts.BatchSize = 1
+	}
+	if opts.Backoff == 0 {
+		opts.Backoff = time.Millisecond
+	}
+
+	w, err := NewWebhook(opts)
+	if err != nil {
+		t.Fatalf("NewWebhook: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+func readDeadLetters(t *testing.T, path string) []deadLetter {
+	t.Helper()
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("open dead letters: %v", err)
+	}
+	defer file.Close()
+
+	var letters []deadLetter
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var letter deadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+			t.Fatalf("decode dead letter %q: %v", scanner.Text(), err)
+		}
+		letters = append(letters, letter)
+	}
+	return letters
+}
+
+func contentRecord(key, value string) *kgo.Record {
+	return &kgo.Record{Topic: "content", Key: []byte(key), Value: []byte(value)}
+}
+
+func TestSignWebhook(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"content-1"}`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "t=1700000000,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := SignWebhook("secret", at, body); got != want {
+		t.Errorf("SignWebhook = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookSignsRequests(t *testing.T) {
+	var recv receiver
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		recv.record(req)
+	}))
+	defer server.Close()
+
+	w := newTestWebhook(t, server.URL, WebhookOptions{Secret: "secret"})
+	if err := w.Write(context.Background(), []*kgo.Record{contentRecord("content-1", `{"id":"content-1"}`)}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if recv.count() != 1 {
+		t.Fatalf("got %d requests, want 1", recv.count())
+	}
+	req, body := recv.requests[0], recv.bodies[0]
+	if got := req.Header.Get(WebhookKeyHeader); got != "content-1" {
+		t.Errorf("key header = %q, want content-1", got)
+	}
+
+	// Recompute the HMAC from the signed timestamp and the body received
+	signature := req.Header.Get(WebhookSignatureHeader)
+	timestamp, _, ok := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+	if !ok {
+		t.Fatalf("malformed signature %q", signature)
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("malformed signature timestamp %q", timestamp)
+	}
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	want := "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+	if signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+	if signature != SignWebhook("secret", time.Unix(unix, 0), body) {
+		t.Errorf("signature does not match SignWebhook")
+	}
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	var recv receiver
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		recv.record(req)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	w := newTestWebhook(t, server.URL, WebhookOptions{MaxRetries: 2})
+	if err := w.Write(context.Background(), []*kgo.Record{contentRecord("content-1", `{"id":"content-1"}`)}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if recv.count() != 3 {
+		t.Fatalf("got %d attempts, want 3", recv.count())
+	}
+	id := recv.requests[0].Header.Get(WebhookIDHeader)
+	if id == "" {
+		t.Fatal("missing webhook ID")
+	}
+	for i, req := range recv.requests {
+		if got := req.Header.Get(WebhookIDHeader); got != id {
+			t.Errorf("attempt %d: webhook ID = %q, want %q", i+1, got, id)
+		}
+	}
+
+	letters := readDeadLetters(t, w.opts.DeadLetterPath)
+	if len(letters) != 1 {
+		t.Fatalf("got %d dead letters, want 1", len(letters))
+	}
+	if letters[0].Status != http.StatusServiceUnavailable || letters[0].Attempts != 3 {
+		t.Errorf("dead letter status %d after %d attempts, want 503 after 3", letters[0].Status, letters[0].Attempts)
+	}
+	if stats := w.Stats(); stats.Retries != 2 || stats.DeadLettered != 1 || stats.Delivered != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestWebhookDeadLettersClientErrors(t *testing.T) {
+	var recv receiver
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		recv.record(req)
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	w := newTestWebhook(t, server.URL, WebhookOptions{MaxRetries: 3, BatchSize: 2})
+	records := []*kgo.Record{
+		contentRecord("content-1", `{"id":"content-1"}`),
+		contentRecord("content-2", `not json`),
+	}
+	if err := w.Write(context.Background(), records); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// The invalid record is sent on its own
+	if recv.count() != 2 {
+		t.Fatalf("got %d attempts, want 2", recv.count())
+	}
+	if got := string(recv.bodies[0]); got != `[{"id":"content-1"}]` {
+		t.Errorf("first body = %s", got)
+	}
+	if got := string(recv.bodies[1]); got != `[not json]` {
+		t.Errorf("second body = %s", got)
+	}
+
+	letters := readDeadLetters(t, w.opts.DeadLetterPath)
+	if len(letters) != 2 {
+		t.Fatalf("got %d dead letters, want 2", len(letters))
+	}
+	for i, letter := range letters {
+		if letter.Status != http.StatusBadRequest || letter.Attempts != 1 || letter.Topic != "content" {
+			t.Errorf("dead letter %d = %+v", i, letter)
+		}
+	}
+	if letters[0].Key != "content-1" || string(letters[0].Value) != `{"id":"content-1"}` {
+		t.Errorf("dead letter 0 = %+v", letters[0])
+	}
+	if string(letters[1].Value) != `"not json"` {
+		t.Errorf("invalid JSON dead-lettered as %s, want a string", letters[1].Value)
+	}
+}
+
+func TestWebhookIsolatesCorruptRecords(t *testing.T) {
+	var recv receiver
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		recv.record(req)
+		recv.mu.Lock()
+		body := recv.bodies[len(recv.bodies)-1]
+		recv.mu.Unlock()
+		if !json.Valid(body) {
+			rw.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	w := newTestWebhook(t, server.URL, WebhookOptions{BatchSize: 3})
+	corrupt := contentRecord("content-3", `{"id":"content-3","view_count":"many"}`)
+	corrupt.Headers = []kgo.RecordHeader{{Key: ChaosHeader, Value: []byte("wrong_type")}}
+	records := []*kgo.Record{
+		contentRecord("content-1", `{"id":"content-1"}`),
+		contentRecord("content-2", `{"id":"content-2"`),
+		corrupt,
+		contentRecord("content-4", `{"id":"content-4"}`),
+		contentRecord("content-5", `{"id":"content-5"}`),
+		contentRecord("content-6", `{"id":"content-6"}`),
+		contentRecord("content-7", `{"id":"content-7"}`),
+	}
+	if err := w.Write(context.Background(), records); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := []string{
+		`[{"id":"content-1"}]`,
+		`[{"id":"content-2"]`,
+		`[{"id":"content-3","view_count":"many"}]`,
+		`[{"id":"content-4"},{"id":"content-5"},{"id":"content-6"}]`,
+		`[{"id":"content-7"}]`,
+	}
+	if recv.count() != len(want) {
+		t.Fatalf("got %d requests, want %d", recv.count(), len(want))
+	}
+	for i, body := range want {
+		if got := string(recv.bodies[i]); got != body {
+			t.Errorf("request %d body = %s, want %s", i, got, body)
+		}
+	}
+
+	letters := readDeadLetters(t, w.opts.DeadLetterPath)
+	if len(letters) != 1 || letters[0].Key != "content-2" {
+		t.Errorf("dead letters = %+v, want only the truncated record", letters)
+	}
+	if stats := w.Stats(); stats.Delivered != 6 {
+		t.Errorf("delivered %d records, want 6", stats.Delivered)
+	}
+}
+
+func TestWebhookRetriesTimeouts(t *testing.T) {
+	var recv receiver
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if recv.record(req) == 1 {
+			// Outlast the client's timeout on the first attempt only
+			select {
+			case <-req.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}))
+	defer server.Close()
+
+	w := newTestWebhook(t, server.URL, WebhookOptions{MaxRetries: 2, Timeout: 50 * time.Millisecond})
+	if err := w.Write(context.Background(), []*kgo.Record{contentRecord("content-1", `{"id":"content-1"}`)}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if recv.count() != 2 {
+		t.Fatalf("got %d attempts, want 2", recv.count())
+	}
+	if stats := w.Stats(); stats.Retries != 1 || stats.Delivered != 1 || stats.DeadLettered != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if letters := readDeadLetters(t, w.opts.DeadLetterPath); len(letters) != 0 {
+		t.Errorf("got %d dead letters, want none", len(letters))
+	}
+}